@@ -62,7 +62,7 @@ func newApp(lg log.Logger, db db.DB, traceStore io.Writer) abci.Application {
 	return app.NewChain(lg, db, traceStore)
 }
 
-func exportAppState(lg log.Logger, db db.DB, traceStore io.Writer) (json.RawMessage, []types.GenesisValidator, error) {
+func exportAppState(lg log.Logger, db db.DB, traceStore io.Writer, height int64) (json.RawMessage, []types.GenesisValidator, error) {
 	logger.SetLogger(lg)
-	return app.NewChain(lg, db, traceStore).ExportAppStateJSON()
+	return app.NewChain(lg, db, traceStore).ExportAppStateJSON(height)
 }
@@ -7,6 +7,19 @@ extern "C" {
     fn __get_arg(idx: usize, offset: usize, value_buf_ptr: *mut u8, value_buf_len: usize) -> i32;
     fn __get_sender(value_buf_ptr: *mut u8, value_buf_len: usize) -> i32;
     fn __get_contract_address(value_buf_ptr: *mut u8, value_buf_len: usize) -> i32;
+    fn __get_block_height() -> i64;
+    fn __get_block_time() -> i64;
+    fn __get_chain_id(value_buf_ptr: *mut u8, value_buf_len: usize) -> i32;
+    fn __get_proposer(value_buf_ptr: *mut u8, value_buf_len: usize) -> i32;
+    fn __get_tx_hash(value_buf_ptr: *mut u8, value_buf_len: usize) -> i32;
+    fn __get_balance(
+        addr: *const u8,
+        addr_size: usize,
+        value_buf_ptr: *mut u8,
+        value_buf_len: usize,
+    ) -> i32;
+    fn __get_value(value_buf_ptr: *mut u8, value_buf_len: usize) -> i32;
+    fn __transfer(to: *const u8, to_size: usize, amount: u64) -> i32;
     fn __read(id: usize, offset: usize, value_buf_ptr: *mut u8, value_buf_len: usize) -> i32;
     fn __call_contract(
         addr: *const u8,
@@ -16,6 +29,33 @@ extern "C" {
         args: *const u8,
         args_size: usize,
     ) -> i32;
+    fn __static_call(
+        addr: *const u8,
+        addr_size: usize,
+        entry: *const u8,
+        entry_size: usize,
+        args: *const u8,
+        args_size: usize,
+    ) -> i32;
+    fn __lock_reentrancy() -> i32;
+    fn __get_call_error() -> i32;
+    fn __revert(code: u32, msg: *const u8, msg_len: usize) -> i32;
+    fn __create_contract(
+        code: *const u8,
+        code_size: usize,
+        args: *const u8,
+        args_size: usize,
+        value_buf_ptr: *mut u8,
+        value_buf_len: usize,
+    ) -> i32;
+    fn __create_contract_from_hash(
+        code_hash: *const u8,
+        code_hash_size: usize,
+        args: *const u8,
+        args_size: usize,
+        value_buf_ptr: *mut u8,
+        value_buf_len: usize,
+    ) -> i32;
 
     fn __set_response(msg: *const u8, len: usize) -> i32;
     fn __log(msg: *const u8, len: usize) -> i32;
@@ -28,6 +68,14 @@ extern "C" {
         value_buf_len: usize,
     ) -> i32;
     fn __write_state(key: *const u8, key_len: usize, value: *const u8, value_len: usize) -> i32;
+    fn __delete_state(key: *const u8, key_len: usize) -> i32;
+    fn __iterate_state(
+        start_ptr: *const u8,
+        start_len: usize,
+        end_ptr: *const u8,
+        end_len: usize,
+        limit: usize,
+    ) -> i32;
 
     fn __keccak256(
         msg: *const u8,
@@ -220,6 +268,65 @@ pub fn get_contract_address() -> Result<Address, Error> {
     }
 }
 
+pub fn get_block_height() -> i64 {
+    unsafe { __get_block_height() }
+}
+
+// get_block_time returns the block time as unix seconds
+pub fn get_block_time() -> i64 {
+    unsafe { __get_block_time() }
+}
+
+pub fn get_chain_id() -> Result<String, Error> {
+    let mut buf = [0u8; BUF_SIZE];
+    match unsafe { __get_chain_id(buf.as_mut_ptr(), buf.len()) } {
+        -1 => Err(from_str("__get_chain_id: failed to __get_chain_id")),
+        n => String::from_utf8(buf[0..n as usize].to_vec())
+            .map_err(|_| from_str("__get_chain_id: invalid utf8")),
+    }
+}
+
+pub fn get_proposer() -> Result<Address, Error> {
+    let mut buf: Address = Default::default();
+    match unsafe { __get_proposer(buf.as_mut_ptr(), 20) } {
+        -1 => Err(from_str("__get_proposer: failed to __get_proposer")),
+        _ => Ok(buf),
+    }
+}
+
+pub fn get_tx_hash() -> Result<[u8; 32], Error> {
+    let mut buf = [0u8; 32];
+    match unsafe { __get_tx_hash(buf.as_mut_ptr(), buf.len()) } {
+        -1 => Err(from_str("__get_tx_hash: failed to __get_tx_hash")),
+        _ => Ok(buf),
+    }
+}
+
+pub fn get_balance(addr: &Address) -> Result<u64, Error> {
+    let mut buf = [0u8; 8];
+    match unsafe { __get_balance(addr.as_ptr(), addr.len(), buf.as_mut_ptr(), buf.len()) } {
+        -1 => Err(from_str("__get_balance: failed to __get_balance")),
+        _ => Ok(u64::from_be_bytes(buf)),
+    }
+}
+
+// get_value returns the amount of coin which the caller has sent to the contract
+pub fn get_value() -> Result<u64, Error> {
+    let mut buf = [0u8; 8];
+    match unsafe { __get_value(buf.as_mut_ptr(), buf.len()) } {
+        -1 => Err(from_str("__get_value: failed to __get_value")),
+        _ => Ok(u64::from_be_bytes(buf)),
+    }
+}
+
+// transfer sends coin from the contract to the address
+pub fn transfer(to: &Address, amount: u64) -> Result<(), Error> {
+    match unsafe { __transfer(to.as_ptr(), to.len(), amount) } {
+        -1 => Err(from_str("__transfer: failed to __transfer")),
+        _ => Ok(()),
+    }
+}
+
 pub fn call_contract<T: FromBytes>(
     addr: &Address,
     entry: &[u8],
@@ -236,10 +343,105 @@ pub fn call_contract<T: FromBytes>(
             a.len(),
         )
     } {
-        -1 => return Err(from_str("failed to call contract")),
+        -1 => return Err(call_error()),
+        id => id as usize,
+    };
+    Ok(T::from_bytes(read_value(id)?)?)
+}
+
+// static_call calls the contract, but the callee cannot modify any state
+pub fn static_call<T: FromBytes>(addr: &Address, entry: &[u8], args: Vec<&[u8]>) -> Result<T, Error> {
+    let a = serialize_args(&args);
+    let id = match unsafe {
+        __static_call(
+            addr.as_ptr(),
+            addr.len(),
+            entry.as_ptr(),
+            entry.len(),
+            a.as_ptr(),
+            a.len(),
+        )
+    } {
+        -1 => return Err(call_error()),
+        id => id as usize,
+    };
+    Ok(T::from_bytes(read_value(id)?)?)
+}
+
+// get_call_error returns the code and the message of the last failed call to other contract
+pub fn get_call_error() -> Option<(i32, String)> {
+    let id = match unsafe { __get_call_error() } {
+        -1 => return None,
         id => id as usize,
     };
+    let v = read_value(id).ok()?;
+    if v.len() < 4 {
+        return None;
+    }
+    let mut code = [0u8; 4];
+    code.copy_from_slice(&v[0..4]);
+    Some((
+        i32::from_be_bytes(code),
+        String::from_utf8_lossy(&v[4..]).to_string(),
+    ))
+}
+
+fn call_error() -> Error {
+    match get_call_error() {
+        Some((code, msg)) => from_str(format!(
+            "failed to call contract: code={} message={}",
+            code, msg
+        )),
+        None => from_str("failed to call contract"),
+    }
+}
+
+// lock_reentrancy makes any reentrant call to the contract fail until the current call returns
+pub fn lock_reentrancy() {
+    unsafe {
+        __lock_reentrancy();
+    }
+}
+
+// create_contract creates a new contract from the wasm module, and returns the address of it
+pub fn create_contract(code: &[u8], args: Vec<&[u8]>) -> Result<Address, Error> {
+    let a = serialize_args(&args);
+    let mut buf: Address = Default::default();
+    match unsafe {
+        __create_contract(
+            code.as_ptr(),
+            code.len(),
+            a.as_ptr(),
+            a.len(),
+            buf.as_mut_ptr(),
+            buf.len(),
+        )
+    } {
+        -1 => Err(from_str("failed to create contract")),
+        _ => Ok(buf),
+    }
+}
+
+// create_contract_from_hash creates a new contract from the hash of the uploaded code, and returns the address of it
+pub fn create_contract_from_hash(code_hash: &[u8], args: Vec<&[u8]>) -> Result<Address, Error> {
+    let a = serialize_args(&args);
+    let mut buf: Address = Default::default();
+    match unsafe {
+        __create_contract_from_hash(
+            code_hash.as_ptr(),
+            code_hash.len(),
+            a.as_ptr(),
+            a.len(),
+            buf.as_mut_ptr(),
+            buf.len(),
+        )
+    } {
+        -1 => Err(from_str("failed to create contract")),
+        _ => Ok(buf),
+    }
+}
 
+fn read_value(id: usize) -> Result<Vec<u8>, Error> {
     let mut buf = [0u8; BUF_SIZE];
     let mut offset = 0;
     let mut val: Vec<u8> = Vec::new();
@@ -257,7 +459,7 @@ pub fn call_contract<T: FromBytes>(
             }
         }
     }
-    Ok(T::from_bytes(val)?)
+    Ok(val)
 }
 
 // format: <elem_num: 4byte>|<elem1_size: 4byte>|<elem1_data>|<elem2_size: 4byte>|<elem2_data>|...
@@ -271,6 +473,24 @@ fn serialize_args(args: &[&[u8]]) -> Vec<u8> {
     bs
 }
 
+fn deserialize_args(bs: &[u8]) -> Result<Vec<Vec<u8>>, Error> {
+    let mut args: Vec<Vec<u8>> = vec![];
+    let mut num_bs = [0u8; 4];
+    num_bs.copy_from_slice(&bs[0..4]);
+    let num = u32::from_be_bytes(num_bs);
+    let mut offset: usize = 4;
+    for _ in 0..num {
+        let mut b = [0u8; 4];
+        b.copy_from_slice(&bs[offset..offset + 4]);
+        let size = u32::from_be_bytes(b) as usize;
+        let mut arg: Vec<u8> = vec![];
+        arg.extend_from_slice(&bs[offset + 4..offset + 4 + size]);
+        offset += 4 + size;
+        args.push(arg);
+    }
+    Ok(args)
+}
+
 pub fn log(b: &[u8]) -> i32 {
     unsafe { __log(b.as_ptr(), b.len()) }
 }
@@ -318,6 +538,50 @@ pub fn write_state(key: &[u8], value: &[u8]) {
     }
 }
 
+pub fn delete_state(key: &[u8]) -> Result<(), Error> {
+    match unsafe { __delete_state(key.as_ptr(), key.len()) } {
+        -1 => Err(from_str("__delete_state: failed to __delete_state")),
+        _ => Ok(()),
+    }
+}
+
+// iterate_state returns key-value pairs whose keys are in [start, end) in ascending order.
+// An empty start or end means an unbounded range. If limit is 0, the host's max limit is used.
+pub fn iterate_state(
+    start: &[u8],
+    end: &[u8],
+    limit: usize,
+) -> Result<Vec<(Vec<u8>, Vec<u8>)>, Error> {
+    let id = match unsafe {
+        __iterate_state(start.as_ptr(), start.len(), end.as_ptr(), end.len(), limit)
+    } {
+        -1 => return Err(from_str("__iterate_state: failed to __iterate_state")),
+        id => id as usize,
+    };
+    let mut elems = deserialize_args(&read_value(id)?)?.into_iter();
+    let mut kvs = vec![];
+    while let (Some(k), Some(v)) = (elems.next(), elems.next()) {
+        kvs.push((k, v));
+    }
+    Ok(kvs)
+}
+
+// iterate_prefix returns key-value pairs whose keys start with prefix.
+pub fn iterate_prefix(prefix: &[u8], limit: usize) -> Result<Vec<(Vec<u8>, Vec<u8>)>, Error> {
+    iterate_state(prefix, &prefix_end(prefix), limit)
+}
+
+fn prefix_end(prefix: &[u8]) -> Vec<u8> {
+    let mut end = prefix.to_vec();
+    while let Some(last) = end.pop() {
+        if last < 0xff {
+            end.push(last + 1);
+            break;
+        }
+    }
+    end
+}
+
 pub fn return_value(v: &[u8]) -> i32 {
     unsafe { __set_response(v.as_ptr(), v.len()) }
 }
@@ -327,28 +591,19 @@ pub fn revert(msg: String) {
     panic!(msg);
 }
 
+// revert_with_code aborts the whole transaction with the code, even if the contract is called by other contract.
+// The code must not be zero.
+pub fn revert_with_code(code: u32, msg: &str) -> ! {
+    unsafe {
+        __revert(code, msg.as_ptr(), msg.len());
+    }
+    unreachable!()
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
 
-    fn deserialize_args(bs: &Vec<u8>) -> Result<Vec<Vec<u8>>, Error> {
-        let mut args: Vec<Vec<u8>> = vec![];
-        let mut num_bs = [0u8; 4];
-        num_bs.copy_from_slice(&bs[0..4]);
-        let num = u32::from_be_bytes(num_bs);
-        let mut offset: usize = 4;
-        for _ in 0..num {
-            let mut b = [0u8; 4];
-            b.copy_from_slice(&bs[offset..offset + 4]);
-            let size = u32::from_be_bytes(b) as usize;
-            let mut arg: Vec<u8> = vec![];
-            arg.extend_from_slice(&bs[offset + 4..offset + 4 + size]);
-            offset += 4 + size;
-            args.push(arg);
-        }
-        Ok(args)
-    }
-
     fn vec_str_to_vec_u8(vs: Vec<&str>) -> Vec<&[u8]> {
         let mut ret: Vec<&[u8]> = vec![];
         for v in vs.iter() {
@@ -368,4 +623,11 @@ mod tests {
             assert_eq!(m, n);
         }
     }
+
+    #[test]
+    fn prefix_end_test() {
+        assert_eq!(prefix_end(b"a"), b"b".to_vec());
+        assert_eq!(prefix_end(&[0x01, 0xff]), vec![0x02]);
+        assert_eq!(prefix_end(&[0xff, 0xff]), Vec::<u8>::new());
+    }
 }
@@ -44,11 +44,13 @@ type BaseApp struct {
 	name        string               // application name from abci.Info
 	db          dbm.DB               // common DB backend
 	cms         sdk.CommitMultiStore // Main (uncached) state
+	mainKey     sdk.StoreKey         // main store key, which holds the chain ID
 	queryRouter QueryRouter          // router for redirecting query calls
 	handler     sdk.Handler
 	txDecoder   sdk.TxDecoder // unmarshal []byte into sdk.Tx
 
 	anteHandler sdk.AnteHandler // ante handler for fee and auth
+	postHandler sdk.PostHandler // post handler for fee refund
 
 	// may be nil
 	initChainer      sdk.InitChainer  // initialize state with validators and state blob
@@ -72,6 +74,10 @@ type BaseApp struct {
 
 var _ abci.Application = (*BaseApp)(nil)
 
+// chainIDKey is a key of the chain ID in the main store.
+// The chain ID is needed to restore checkState after restart.
+var chainIDKey = []byte("chain_id")
+
 // NewBaseApp returns a reference to an initialized BaseApp.
 //
 // TODO: Determine how to use a flexible and robust configuration paradigm that
@@ -173,8 +179,9 @@ func (app *BaseApp) initFromStore(mainKey sdk.StoreKey) error {
 	if main == nil {
 		return errors.New("baseapp expects MultiStore with 'main' KVStore")
 	}
+	app.mainKey = mainKey
 	// Needed for `gaiad export`, which inits from store but never calls initchain
-	app.setCheckState(abci.Header{})
+	app.setCheckState(abci.Header{ChainID: string(main.Get(chainIDKey))})
 
 	app.Seal()
 
@@ -245,6 +252,9 @@ func (app *BaseApp) InitChain(req abci.RequestInitChain) (res abci.ResponseInitC
 	// Initialize the deliver state and check state with ChainID and run initChain
 	app.setDeliverState(abci.Header{ChainID: req.ChainId})
 	app.setCheckState(abci.Header{ChainID: req.ChainId})
+	if app.mainKey != nil {
+		app.deliverState.ms.GetKVStore(app.mainKey).Set(chainIDKey, []byte(req.ChainId))
+	}
 
 	if app.initChainer == nil {
 		return
@@ -489,6 +499,7 @@ func (app *BaseApp) getContextForTx(mode runTxMode, txBytes []byte) (ctx sdk.Con
 		WithVoteInfos(app.voteInfos)
 	if mode == runTxModeSimulate {
 		ctx, _ = ctx.CacheContext()
+		ctx = ctx.WithIsSimulate(true)
 	}
 	return
 }
@@ -592,14 +603,18 @@ func (app *BaseApp) runTx(mode runTxMode, txBytes []byte, tx sdk.Tx) (result sdk
 	result = app.handler(runMsgCtx, tx)
 	result.GasWanted = gasWanted
 
-	if mode == runTxModeSimulate || mode == runTxModeCheck { // XXX
-		return
+	// only update state if all messages pass
+	if mode != runTxModeDeliver || !result.IsOK() {
+		// the post handler runs on the state without the changes by the handler
+		runMsgCtx, msCache = app.cacheTxContext(ctx, txBytes)
 	}
 
-	// only update state if all messages pass
-	if result.IsOK() {
-		msCache.Write()
+	if app.postHandler != nil {
+		if err := app.postHandler(runMsgCtx, tx, result, (mode == runTxModeSimulate)); err != nil {
+			return err.Result()
+		}
 	}
+	msCache.Write()
 
 	return
 }
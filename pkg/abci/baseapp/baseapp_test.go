@@ -131,6 +131,27 @@ func TestLoadVersion(t *testing.T) {
 	testLoadVersionHelper(t, app, int64(2), commitID2)
 }
 
+// Test that the chain ID is restored after reloading the app.
+func TestLoadChainID(t *testing.T) {
+	logger := defaultLogger()
+	db := dbm.NewMemDB()
+	name := t.Name()
+	chainID := "test-chain"
+	capKey := sdk.NewKVStoreKey("main")
+
+	app := NewBaseApp(name, logger, db, nil)
+	app.MountStoresIAVL(capKey)
+	require.Nil(t, app.LoadLatestVersion(capKey))
+	app.InitChain(abci.RequestInitChain{ChainId: chainID})
+	app.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1, ChainID: chainID}})
+	app.Commit()
+
+	app = NewBaseApp(name, logger, db, nil)
+	app.MountStoresIAVL(capKey)
+	require.Nil(t, app.LoadLatestVersion(capKey))
+	require.Equal(t, chainID, app.checkState.ctx.ChainID())
+}
+
 func testLoadVersionHelper(t *testing.T, app *BaseApp, expectedHeight int64, expectedID sdk.CommitID) {
 	lastHeight := app.LastBlockHeight()
 	lastID := app.LastCommitID()
@@ -681,3 +702,59 @@ func TestBaseAppAnteHandler(t *testing.T) {
 	app.EndBlock(abci.RequestEndBlock{})
 	app.Commit()
 }
+
+func TestBaseAppPostHandler(t *testing.T) {
+	anteKey := []byte("ante-key")
+	deliverKey := []byte("deliver-key")
+	postKey := []byte("post-key")
+	failOnPost := false
+	opt := func(bapp *BaseApp) {
+		bapp.SetAnteHandler(anteHandlerTxTest(t, capKey1, anteKey))
+		bapp.SetHandler(handlerMsgCounter(t, capKey1, deliverKey))
+		bapp.SetPostHandler(func(ctx sdk.Context, tx sdk.Tx, result sdk.Result, simulate bool) sdk.Error {
+			incrementingCounter(t, ctx.KVStore(capKey1), postKey)
+			if failOnPost {
+				return sdk.ErrInternal("post handler failure")
+			}
+			return nil
+		})
+	}
+
+	cdc := codec.New()
+	app := setupBaseApp(t, opt)
+	app.InitChain(abci.RequestInitChain{})
+	registerTestCodec(cdc)
+	app.BeginBlock(abci.RequestBeginBlock{})
+
+	var deliver = func(failOnHandler bool) abci.ResponseDeliverTx {
+		tx := newTxCounter()
+		tx.setFailOnHandler(failOnHandler)
+		txBytes, err := cdc.MarshalBinaryLengthPrefixed(tx)
+		require.NoError(t, err)
+		return app.DeliverTx(abci.RequestDeliverTx{Tx: txBytes})
+	}
+	var requireCounters = func(ante, deliver, post int64) {
+		store := app.getState(runTxModeDeliver).ctx.KVStore(capKey1)
+		require.Equal(t, ante, getIntFromStore(store, anteKey))
+		require.Equal(t, deliver, getIntFromStore(store, deliverKey))
+		require.Equal(t, post, getIntFromStore(store, postKey))
+	}
+
+	res := deliver(false)
+	require.True(t, res.IsOK(), fmt.Sprintf("%v", res))
+	requireCounters(1, 1, 1)
+
+	// the post handler runs even if the handler fails
+	res = deliver(true)
+	require.False(t, res.IsOK(), fmt.Sprintf("%v", res))
+	requireCounters(2, 1, 2)
+
+	// if the post handler fails, only the changes by the ante handler are written
+	failOnPost = true
+	res = deliver(false)
+	require.False(t, res.IsOK(), fmt.Sprintf("%v", res))
+	requireCounters(3, 1, 2)
+
+	app.EndBlock(abci.RequestEndBlock{})
+	app.Commit()
+}
@@ -43,6 +43,7 @@ func NewContext(ms MultiStore, header abci.Header, isCheckTx bool, logger log.Lo
 	c = c.WithBlockHeight(header.Height)
 	c = c.WithChainID(header.ChainID)
 	c = c.WithIsCheckTx(isCheckTx)
+	c = c.WithIsSimulate(false)
 	c = c.WithTxBytes(nil)
 	c = c.WithLogger(logger)
 	c = c.WithVoteInfos(nil)
@@ -141,6 +142,7 @@ const (
 	contextKeyGasMeter
 	contextKeyMinimumFees
 	contextKeyTxIndex
+	contextKeyIsSimulate
 )
 
 func (c Context) MultiStore() MultiStore {
@@ -169,6 +171,8 @@ func (c Context) GasMeter() GasMeter { return c.Value(contextKeyGasMeter).(GasMe
 
 func (c Context) IsCheckTx() bool { return c.Value(contextKeyIsCheckTx).(bool) }
 
+func (c Context) IsSimulate() bool { return c.Value(contextKeyIsSimulate).(bool) }
+
 func (c Context) TxIndex() uint32 {
 	return c.Value(contextKeyTxIndex).(uint32)
 }
@@ -226,6 +230,10 @@ func (c Context) WithIsCheckTx(isCheckTx bool) Context {
 	return c.withValue(contextKeyIsCheckTx, isCheckTx)
 }
 
+func (c Context) WithIsSimulate(isSimulate bool) Context {
+	return c.withValue(contextKeyIsSimulate, isSimulate)
+}
+
 func (c Context) WithTxIndex(idx uint32) Context {
 	return c.withValue(contextKeyTxIndex, idx)
 }
@@ -1,5 +1,7 @@
 package types
 
+import "math"
+
 // Gas consumption descriptors.
 const (
 	GasIterNextCostFlatDesc = "IterNextFlat"
@@ -34,6 +36,7 @@ type ErrorGasOverflow struct {
 // GasMeter interface to track gas consumption
 type GasMeter interface {
 	GasConsumed() Gas
+	Limit() Gas
 	ConsumeGas(amount Gas, descriptor string)
 }
 
@@ -54,6 +57,10 @@ func (g *basicGasMeter) GasConsumed() Gas {
 	return g.consumed
 }
 
+func (g *basicGasMeter) Limit() Gas {
+	return g.limit
+}
+
 func (g *basicGasMeter) ConsumeGas(amount Gas, descriptor string) {
 	var overflow bool
 
@@ -83,6 +90,11 @@ func (g *infiniteGasMeter) GasConsumed() Gas {
 	return g.consumed
 }
 
+// Limit returns the max value of Gas because infiniteGasMeter has no limit
+func (g *infiniteGasMeter) Limit() Gas {
+	return math.MaxUint64
+}
+
 func (g *infiniteGasMeter) ConsumeGas(amount Gas, descriptor string) {
 	var overflow bool
 
@@ -21,6 +21,7 @@ func TestGasMeter(t *testing.T) {
 
 	for tcnum, tc := range cases {
 		meter := NewGasMeter(tc.limit)
+		require.Equal(t, tc.limit, meter.Limit())
 		used := uint64(0)
 
 		for unum, usage := range tc.usage {
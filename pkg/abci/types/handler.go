@@ -6,3 +6,8 @@ type Handler func(ctx Context, tx Tx) Result
 // AnteHandler authenticates transactions, before their internal messages are handled.
 // If newCtx.IsZero(), ctx is used instead.
 type AnteHandler func(ctx Context, tx Tx, simulate bool) (newCtx Context, result Result, abort bool)
+
+// PostHandler runs after the handler regardless of its result, e.g. to refund unused fees.
+// The state changes by PostHandler are written even if the handler fails.
+// If PostHandler returns an error, the tx fails and only the state changes by AnteHandler are written.
+type PostHandler func(ctx Context, tx Tx, result Result, simulate bool) Error
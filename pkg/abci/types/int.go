@@ -556,6 +556,15 @@ func AddUint64Overflow(a, b uint64) (uint64, bool) {
 	return a + b, false
 }
 
+// MulUint64Overflow returns the product of a and b, and whether the multiplication overflows
+func MulUint64Overflow(a, b uint64) (uint64, bool) {
+	if a != 0 && b > math.MaxUint64/a {
+		return 0, true
+	}
+
+	return a * b, false
+}
+
 // intended to be used with require/assert:  require.True(IntEq(...))
 func IntEq(t *testing.T, exp, got Int) (*testing.T, bool, string, string, string) {
 	return t, exp.Equal(got), "expected:\t%v\ngot:\t\t%v", exp.String(), got.String()
@@ -640,3 +640,29 @@ func TestAddUint64Overflow(t *testing.T) {
 		)
 	}
 }
+
+func TestMulUint64Overflow(t *testing.T) {
+	testCases := []struct {
+		a, b     uint64
+		result   uint64
+		overflow bool
+	}{
+		{0, 0, 0, false},
+		{0, math.MaxUint64, 0, false},
+		{100, 100, 10000, false},
+		{math.MaxUint64 / 2, 2, math.MaxUint64 - 1, false},
+		{math.MaxUint64/2 + 1, 2, 0, true},
+	}
+
+	for i, tc := range testCases {
+		res, overflow := MulUint64Overflow(tc.a, tc.b)
+		require.Equal(
+			t, tc.overflow, overflow,
+			"invalid overflow result; tc: #%d, a: %d, b: %d", i, tc.a, tc.b,
+		)
+		require.Equal(
+			t, tc.result, res,
+			"invalid uint64 result; tc: #%d, a: %d, b: %d", i, tc.a, tc.b,
+		)
+	}
+}
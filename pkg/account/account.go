@@ -1,6 +1,9 @@
 package account
 
 import (
+	"bytes"
+	"sort"
+
 	"github.com/bluele/hypermint/pkg/abci/types"
 	"github.com/bluele/hypermint/pkg/util"
 	"github.com/ethereum/go-ethereum/common"
@@ -9,6 +12,15 @@ import (
 type Account struct {
 	Address common.Address
 	Amount  uint64
+	// Nonce is the next nonce that the account should use
+	Nonce uint64 `json:",omitempty"`
+}
+
+var noncePrefix = []byte("nonce/")
+
+// NonceKey returns a key for the nonce of given address
+func NonceKey(addr common.Address) []byte {
+	return append(append([]byte{}, noncePrefix...), addr.Bytes()...)
 }
 
 type AccountMapper interface {
@@ -16,6 +28,12 @@ type AccountMapper interface {
 	AddBalance(types.Context, common.Address, uint64) (uint64, error)
 	SubBalance(types.Context, common.Address, uint64) (uint64, error)
 	Transfer(types.Context, common.Address, uint64, common.Address) error
+	GetNonce(types.Context, common.Address) (uint64, error)
+	IncrNonce(types.Context, common.Address) (uint64, error)
+	SetNonce(types.Context, common.Address, uint64)
+	Accounts(types.Context) ([]Account, error)
+	GetFeeRecipient(types.Context) (common.Address, bool)
+	SetFeeRecipient(types.Context, common.Address)
 }
 
 type accountMapper struct {
@@ -49,7 +67,10 @@ func (am *accountMapper) addBalance(kvs types.KVStore, addr common.Address, amou
 	if err != nil && err != ErrAccountNotFound {
 		return 0, err
 	}
-	total := bl + amount
+	total, overflow := types.AddUint64Overflow(bl, amount)
+	if overflow {
+		return 0, ErrBalanceOverflow
+	}
 	return total, setBalance(kvs, addr, total)
 }
 
@@ -80,6 +101,76 @@ func (am *accountMapper) Transfer(ctx types.Context, from common.Address, amount
 	return nil
 }
 
+// GetNonce returns the next nonce that given address should use
+func (am *accountMapper) GetNonce(ctx types.Context, addr common.Address) (uint64, error) {
+	return am.getNonce(am.getStore(ctx), addr)
+}
+
+func (am *accountMapper) getNonce(kvs types.KVStore, addr common.Address) (uint64, error) {
+	v := kvs.Get(NonceKey(addr))
+	if v == nil {
+		return 0, nil
+	}
+	return util.BytesToUint64(v)
+}
+
+// IncrNonce increments the nonce of given address and returns the updated value
+func (am *accountMapper) IncrNonce(ctx types.Context, addr common.Address) (uint64, error) {
+	kvs := am.getStore(ctx)
+	nonce, err := am.getNonce(kvs, addr)
+	if err != nil {
+		return 0, err
+	}
+	nonce++
+	kvs.Set(NonceKey(addr), util.Uint64ToBytes(nonce))
+	return nonce, nil
+}
+
+// SetNonce sets the next nonce that given address should use
+func (am *accountMapper) SetNonce(ctx types.Context, addr common.Address, nonce uint64) {
+	am.getStore(ctx).Set(NonceKey(addr), util.Uint64ToBytes(nonce))
+}
+
+// Accounts returns all accounts which have a balance or a nonce in the order of their addresses
+func (am *accountMapper) Accounts(ctx types.Context) ([]Account, error) {
+	accs := make(map[common.Address]*Account)
+	get := func(addr common.Address) *Account {
+		if _, ok := accs[addr]; !ok {
+			accs[addr] = &Account{Address: addr}
+		}
+		return accs[addr]
+	}
+
+	it := am.getStore(ctx).Iterator(nil, nil)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		k := it.Key()
+		switch {
+		case len(k) == common.AddressLength:
+			amount, err := util.BytesToUint64(it.Value())
+			if err != nil {
+				return nil, err
+			}
+			get(common.BytesToAddress(k)).Amount = amount
+		case len(k) == len(noncePrefix)+common.AddressLength && bytes.HasPrefix(k, noncePrefix):
+			nonce, err := util.BytesToUint64(it.Value())
+			if err != nil {
+				return nil, err
+			}
+			get(common.BytesToAddress(k[len(noncePrefix):])).Nonce = nonce
+		}
+	}
+
+	ret := make([]Account, 0, len(accs))
+	for _, acc := range accs {
+		ret = append(ret, *acc)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return bytes.Compare(ret[i].Address.Bytes(), ret[j].Address.Bytes()) < 0
+	})
+	return ret, nil
+}
+
 func (am *accountMapper) getStore(ctx types.Context) types.KVStore {
 	return ctx.KVStore(am.storeKey)
 }
@@ -1,10 +1,16 @@
 package account
 
 import (
+	"math"
 	"testing"
 
 	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
 	amino "github.com/tendermint/go-amino"
+	abci "github.com/tendermint/tendermint/abci/types"
+	cmn "github.com/tendermint/tendermint/libs/common"
 )
 
 var (
@@ -17,3 +23,68 @@ func TestTransfer(t *testing.T) {
 
 	// am.AddBalance()
 }
+
+func TestNonce(t *testing.T) {
+	assert := assert.New(t)
+	cms, err := testutil.GetTestCommitMultiStore(testStoreKey)
+	assert.NoError(err)
+	ctx := types.NewContext(cms, abci.Header{}, false, nil)
+	am := NewAccountMapper(testStoreKey)
+
+	addr := common.BytesToAddress(cmn.RandBytes(20))
+	nonce, err := am.GetNonce(ctx, addr)
+	assert.NoError(err)
+	assert.EqualValues(0, nonce)
+
+	for i := 1; i <= 3; i++ {
+		nonce, err = am.IncrNonce(ctx, addr)
+		assert.NoError(err)
+		assert.EqualValues(i, nonce)
+	}
+	nonce, err = am.GetNonce(ctx, addr)
+	assert.NoError(err)
+	assert.EqualValues(3, nonce)
+
+	// nonce must not be confused with the balance of the same address
+	_, err = am.GetBalance(ctx, addr)
+	assert.Equal(ErrAccountNotFound, err)
+}
+
+func TestBalanceOverflow(t *testing.T) {
+	assert := assert.New(t)
+	cms, err := testutil.GetTestCommitMultiStore(testStoreKey)
+	assert.NoError(err)
+	ctx := types.NewContext(cms, abci.Header{}, false, nil)
+	am := NewAccountMapper(testStoreKey)
+
+	addr := common.BytesToAddress(cmn.RandBytes(20))
+	// a balance over MaxInt64 can be read
+	_, err = am.AddBalance(ctx, addr, math.MaxUint64-1)
+	assert.NoError(err)
+	b, err := am.GetBalance(ctx, addr)
+	assert.NoError(err)
+	assert.EqualValues(uint64(math.MaxUint64-1), b)
+
+	_, err = am.AddBalance(ctx, addr, 2)
+	assert.Equal(ErrBalanceOverflow, err)
+	b, err = am.AddBalance(ctx, addr, 1)
+	assert.NoError(err)
+	assert.EqualValues(uint64(math.MaxUint64), b)
+}
+
+func TestFeeRecipient(t *testing.T) {
+	assert := assert.New(t)
+	cms, err := testutil.GetTestCommitMultiStore(testStoreKey)
+	assert.NoError(err)
+	ctx := types.NewContext(cms, abci.Header{}, false, nil)
+	am := NewAccountMapper(testStoreKey)
+
+	_, ok := am.GetFeeRecipient(ctx)
+	assert.False(ok)
+
+	addr := common.BytesToAddress(cmn.RandBytes(20))
+	am.SetFeeRecipient(ctx, addr)
+	recipient, ok := am.GetFeeRecipient(ctx)
+	assert.True(ok)
+	assert.Equal(addr, recipient)
+}
@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrAccountNotFound  = errors.New("account not found")
 	ErrNotEnoughBalance = errors.New("not enough balance")
+	ErrBalanceOverflow  = errors.New("balance overflow")
 )
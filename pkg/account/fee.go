@@ -0,0 +1,26 @@
+package account
+
+import (
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// FeeCollectorAddress is the address of the account which holds tx fees until they are distributed
+var FeeCollectorAddress = common.BytesToAddress([]byte("fee_collector"))
+
+var feeRecipientKey = []byte("fee_recipient")
+
+// GetFeeRecipient returns the address which receives collected fees.
+// If it isn't configured, this returns false.
+func (am *accountMapper) GetFeeRecipient(ctx types.Context) (common.Address, bool) {
+	v := am.getStore(ctx).Get(feeRecipientKey)
+	if v == nil {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(v), true
+}
+
+// SetFeeRecipient sets the address which receives collected fees
+func (am *accountMapper) SetFeeRecipient(ctx types.Context, addr common.Address) {
+	am.getStore(ctx).Set(feeRecipientKey, addr.Bytes())
+}
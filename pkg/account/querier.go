@@ -0,0 +1,42 @@
+package account
+
+import (
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/util"
+	"github.com/ethereum/go-ethereum/common"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+const (
+	QuerierRoute = "account"
+
+	QueryNonce = "nonce"
+)
+
+// NewQuerier returns a querier for account state
+func NewQuerier(am AccountMapper) types.Querier {
+	return func(ctx types.Context, path []string, req abci.RequestQuery) ([]byte, types.Error) {
+		if len(path) == 0 {
+			return nil, types.ErrUnknownRequest("no query path provided")
+		}
+		switch path[0] {
+		case QueryNonce:
+			return queryNonce(ctx, am, req)
+		default:
+			return nil, types.ErrUnknownRequest(fmt.Sprintf("unknown account query endpoint: %v", path[0]))
+		}
+	}
+}
+
+func queryNonce(ctx types.Context, am AccountMapper, req abci.RequestQuery) ([]byte, types.Error) {
+	if len(req.Data) != common.AddressLength {
+		return nil, types.ErrUnknownRequest(fmt.Sprintf("invalid address length: %v", len(req.Data)))
+	}
+	nonce, err := am.GetNonce(ctx, common.BytesToAddress(req.Data))
+	if err != nil {
+		return nil, types.ErrInternal(err.Error())
+	}
+	return util.Uint64ToBytes(nonce), nil
+}
@@ -2,7 +2,6 @@ package app
 
 import (
 	"encoding/json"
-	"errors"
 	"io"
 	"os"
 
@@ -10,6 +9,7 @@ import (
 	"github.com/spf13/viper"
 
 	amino "github.com/tendermint/go-amino"
+	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/libs/common"
 	"github.com/tendermint/tendermint/libs/log"
@@ -24,13 +24,27 @@ import (
 	"github.com/bluele/hypermint/pkg/contract"
 	"github.com/bluele/hypermint/pkg/db"
 	"github.com/bluele/hypermint/pkg/handler"
+	"github.com/bluele/hypermint/pkg/permission"
 	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/bluele/hypermint/pkg/validator"
 )
 
 const (
 	flagAddress    = "address"
 	flagName       = "name"
 	flagClientHome = "home-client"
+
+	flagGenesisContracts = "genesis-contracts"
+
+	// FlagContractDebug enables debug mode of contract execution.
+	// The debug info is returned only in the simulation.
+	FlagContractDebug = "contract.debug"
+	// FlagContractDeliverLogs makes the results of delivered txs include the logs of contracts.
+	FlagContractDeliverLogs = "contract.deliver-logs"
+	// FlagContractEngine is the engine which executes contracts. It must be the vm_engine of the genesis.
+	FlagContractEngine = "contract.engine"
+	// FlagMinGasPrice is the min gas price of the txs which the node accepts into its mempool.
+	FlagMinGasPrice = "min-gas-price"
 )
 
 var (
@@ -38,9 +52,11 @@ var (
 	DefaultCLIHome  = os.ExpandEnv("$HOME/.hmcli")
 	DefaultNodeHome = os.ExpandEnv("$HOME/.hmd")
 
-	MainStoreKey     = sdk.NewKVStoreKey("main")
-	ContractStoreKey = sdk.NewKVStoreKey("contract")
-	TxIndexStoreKey  = sdk.NewTransientStoreKey("tx_index")
+	MainStoreKey       = sdk.NewKVStoreKey("main")
+	ContractStoreKey   = sdk.NewKVStoreKey("contract")
+	ValidatorStoreKey  = sdk.NewKVStoreKey("validator")
+	PermissionStoreKey = sdk.NewKVStoreKey("permission")
+	TxIndexStoreKey    = sdk.NewTransientStoreKey("tx_index")
 )
 
 type Chain struct {
@@ -52,7 +68,14 @@ type Chain struct {
 	// keys to access the substores
 	capKeyMainStore *sdk.KVStoreKey
 	contractStore   *sdk.KVStoreKey
+	validatorStore  *sdk.KVStoreKey
+	permissionStore *sdk.KVStoreKey
 	txIndexStore    *sdk.TransientStoreKey
+
+	am   account.AccountMapper
+	envm *contract.EnvManager
+	vm   validator.ValidatorMapper
+	pm   permission.PermissionMapper
 }
 
 func NewChain(logger log.Logger, tmdb tmdb.DB, traceStore io.Writer) *Chain {
@@ -62,48 +85,94 @@ func NewChain(logger log.Logger, tmdb tmdb.DB, traceStore io.Writer) *Chain {
 		cdc:             cdc,
 		capKeyMainStore: MainStoreKey,
 		contractStore:   ContractStoreKey,
+		validatorStore:  ValidatorStoreKey,
+		permissionStore: PermissionStoreKey,
 		txIndexStore:    TxIndexStoreKey,
 	}
 	am := account.NewAccountMapper(c.capKeyMainStore)
 	cm := contract.NewContractMapper(c.contractStore)
 	cmn := contract.NewContractManager(cm)
 	sm := db.NewStateManager(c.contractStore)
-	envm := contract.NewEnvManager(c.contractStore, cm)
+	envm := contract.NewEnvManager(c.contractStore, cm, am)
+	envm.SetDebug(viper.GetBool(FlagContractDebug))
+	envm.SetDeliverLogs(viper.GetBool(FlagContractDeliverLogs))
+	engine, err := contract.NewEngine(viper.GetString(FlagContractEngine))
+	if err != nil {
+		common.Exit(err.Error())
+	}
+	envm.SetEngine(engine)
 	txm := transaction.NewTxIndexMapper(c.txIndexStore)
-
-	c.SetHandler(handler.NewHandler(txm, am, cmn, envm, sm))
-	c.SetAnteHandler(handler.NewAnteHandler(am))
-	c.SetInitChainer(GetInitChainer(am))
-
-	err := c.mountStores()
+	vm := validator.NewValidatorMapper(c.validatorStore)
+	pm := permission.NewPermissionMapper(c.permissionStore)
+	minGasPrice, err := getUint64(FlagMinGasPrice)
 	if err != nil {
 		common.Exit(err.Error())
 	}
+	// contracts cannot bypass the roles by calling or creating other contracts
+	envm.SetPermissionMapper(pm)
+	c.am, c.envm, c.vm, c.pm = am, envm, vm, pm
+
+	c.SetHandler(handler.NewHandler(txm, am, cmn, envm, sm, vm, pm))
+	c.SetAnteHandler(handler.NewAnteHandler(am, pm, minGasPrice))
+	c.SetPostHandler(handler.NewPostHandler(am))
+	c.SetEndBlocker(handler.ComposeEndBlockers(
+		handler.NewEndBlocker(am),
+		handler.NewValidatorEndBlocker(vm),
+	))
+	c.SetInitChainer(GetInitChainer(am, cmn, envm, sm, vm, pm))
+	c.QueryRouter().AddRoute(account.QuerierRoute, account.NewQuerier(am))
+	c.QueryRouter().AddRoute(permission.QuerierRoute, permission.NewQuerier(pm))
+
+	err = c.mountStores()
+	if err != nil {
+		common.Exit(err.Error())
+	}
+	// the engine of the chain is stored by the genesis, so it is checked after the genesis
+	if c.LastBlockHeight() > 0 {
+		if err := envm.CheckChainEngine(c.NewContext(true, abci.Header{})); err != nil {
+			common.Exit(err.Error())
+		}
+	}
 
 	return c
 }
 
 func (c *Chain) mountStores() error {
 	keys := []*sdk.KVStoreKey{
-		c.capKeyMainStore, c.contractStore,
+		c.capKeyMainStore, c.contractStore, c.validatorStore, c.permissionStore,
 	}
 
 	c.MountStoresIAVL(keys...)
 	c.MountStoresTransient(c.txIndexStore)
 
-	for _, key := range keys {
-		if err := c.LoadLatestVersion(key); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return c.LoadLatestVersion(c.capKeyMainStore)
 }
 
-func (c *Chain) ExportAppStateJSON() (json.RawMessage, []types.GenesisValidator, error) {
-	// TODO: Implement
-	// Currently non-functional, just enough to compile
-	return nil, nil, errors.New("not implemented error")
+// ExportAppStateJSON exports the app state and the validators at given height. If height is 0, the latest state is exported.
+func (c *Chain) ExportAppStateJSON(height int64) (json.RawMessage, []types.GenesisValidator, error) {
+	if height > 0 {
+		if err := c.LoadVersion(height, c.capKeyMainStore); err != nil {
+			return nil, nil, err
+		}
+	}
+	ctx := c.NewContext(true, abci.Header{Height: c.LastBlockHeight()})
+	gs, err := ExportGenesisState(ctx, c.am, c.envm, c.vm, c.pm)
+	if err != nil {
+		return nil, nil, err
+	}
+	appState, err := json.MarshalIndent(gs, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	var validators []types.GenesisValidator
+	for _, v := range c.vm.Validators(ctx) {
+		validators = append(validators, types.GenesisValidator{
+			Address: v.Address(),
+			PubKey:  v.CryptoPubKey(),
+			Power:   int64(v.Power),
+		})
+	}
+	return appState, validators, nil
 }
 
 //_____________________________________________________________________
@@ -126,6 +195,11 @@ type AppInit struct {
 
 func NewAppInit() AppInit {
 	fsAppGenState := pflag.NewFlagSet("", pflag.ContinueOnError)
+	fsAppGenState.String(flagGenesisContracts, "", "JSON file of the contracts which are deployed at the genesis")
+	fsAppGenState.String(FlagGenesisAccounts, "", "JSON file which maps addresses to their genesis balances")
+	fsAppGenState.Uint64(FlagGenesisBalance, genesisBalance, "genesis balance of each address of the genesis transactions")
+	fsAppGenState.Uint64(FlagTotalSupply, 0, "expected total of the genesis balances. 0 disables the check")
+	fsAppGenState.String(FlagFeeRecipient, "", "address which receives collected fees. if not specified, the first address of the genesis transactions receives them")
 	fsAppGenTx := pflag.NewFlagSet("", pflag.ContinueOnError)
 	fsAppGenTx.String(flagAddress, "", "address, required")
 	fsAppGenTx.String(flagClientHome, DefaultCLIHome,
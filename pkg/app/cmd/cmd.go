@@ -38,8 +38,7 @@ func AddServerCommands(
 		vmCmd(ctx),
 		lineBreak,
 		tendermintCmd,
-		// TODO impl export cmd
-		// server.ExportCmd(ctx, cdc, appExport),
+		exportCmd(ctx, cdc, appExport),
 		lineBreak,
 		versionCmd,
 	)
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	amino "github.com/tendermint/go-amino"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/bluele/hypermint/pkg/app"
+)
+
+const (
+	flagHeight = "height"
+)
+
+// exportCmd prints the genesis file whose app state is the state of the chain at given height
+func exportCmd(ctx *app.Context, cdc *amino.Codec, appExporter app.AppExporter) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export state to JSON",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			viper.BindPFlags(cmd.Flags())
+			home := viper.GetString("home")
+			traceStore := viper.GetString(flagTraceStore)
+			height := viper.GetInt64(flagHeight)
+			if height < 0 {
+				return fmt.Errorf("height must not be negative: %v", height)
+			}
+
+			appState, validators, err := appExporter(home, ctx.Logger, traceStore, height)
+			if err != nil {
+				return fmt.Errorf("failed to export state: %v", err)
+			}
+
+			doc, err := tmtypes.GenesisDocFromFile(ctx.Config.GenesisFile())
+			if err != nil {
+				return err
+			}
+			doc.AppState = appState
+			// the validators of the genesis are kept if the state has no validators
+			if validators != nil {
+				doc.Validators = validators
+			}
+
+			out, err := app.MarshalJSONIndent(cdc, doc)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+			return nil
+		},
+	}
+	cmd.Flags().Int64(flagHeight, 0, "Height of the state to export. 0 means the latest height")
+	cmd.Flags().String(flagTraceStore, "", "Enable KVStore tracing to an output file")
+	return cmd
+}
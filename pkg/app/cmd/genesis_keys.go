@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/app"
+	"github.com/bluele/hypermint/pkg/util"
+)
+
+const (
+	flagGenesisKeys         = "genesis-keys"
+	flagGenesisKeysBalance  = "genesis-keys-balance"
+	flagGenesisKeysPassword = "genesis-keys-password"
+
+	// genesisAccountsFile is the file in the client directory which lists the funded accounts
+	genesisAccountsFile = "genesis_accounts.json"
+)
+
+func addGenesisKeysFlags(cmd *cobra.Command) {
+	cmd.Flags().Int(flagGenesisKeys, 0, "number of funded accounts which are generated into the keystore of the client directory")
+	cmd.Flags().Int64(flagGenesisKeysBalance, 1000000, "genesis balance of each generated account")
+	cmd.Flags().String(flagGenesisKeysPassword, "", "password of the generated accounts")
+}
+
+// generateGenesisKeys creates the funded accounts in the keystore of the first directory, and copies them to the others.
+// The accounts are written to the genesis accounts file in the first directory with the accounts of --genesis-accounts,
+// and the file replaces --genesis-accounts so that the genesis funds them.
+// If the genesis is not created after all, cleanup removes the accounts and restores the genesis accounts file.
+func generateGenesisKeys(dirs []string) (cleanup func(), err error) {
+	cleanup = func() {}
+	n := viper.GetInt(flagGenesisKeys)
+	if n <= 0 {
+		return cleanup, nil
+	}
+	balance := viper.GetInt64(flagGenesisKeysBalance)
+	if balance < 0 {
+		return cleanup, fmt.Errorf("%v must not be negative: %v", flagGenesisKeysBalance, balance)
+	}
+
+	var accs []account.Account
+	if path := viper.GetString(app.FlagGenesisAccounts); path != "" {
+		if accs, err = app.ReadGenesisAccounts(path); err != nil {
+			return cleanup, err
+		}
+	}
+
+	var undo []func()
+	cleanup = func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+	defer func() {
+		if err != nil {
+			cleanup()
+		}
+	}()
+	var create = func(path string) {
+		undo = append(undo, func() { os.Remove(path) })
+	}
+
+	ks := keystore.NewKeyStore(dirs[0], keystore.StandardScryptN, keystore.StandardScryptP)
+	for _, dir := range dirs[1:] {
+		if err = os.MkdirAll(dir, nodeDirPerm); err != nil {
+			return cleanup, err
+		}
+	}
+	for i := 0; i < n; i++ {
+		acc, err := ks.NewAccount(viper.GetString(flagGenesisKeysPassword))
+		if err != nil {
+			return cleanup, err
+		}
+		create(acc.URL.Path)
+		for _, dir := range dirs[1:] {
+			path := filepath.Join(dir, filepath.Base(acc.URL.Path))
+			if err := util.CopyFile(acc.URL.Path, path); err != nil {
+				return cleanup, err
+			}
+			create(path)
+		}
+		accs = append(accs, account.Account{Address: acc.Address, Amount: uint64(balance)})
+	}
+
+	path := filepath.Join(dirs[0], genesisAccountsFile)
+	if b, err := ioutil.ReadFile(path); err == nil {
+		undo = append(undo, func() { ioutil.WriteFile(path, b, 0644) })
+	} else if os.IsNotExist(err) {
+		create(path)
+	} else {
+		return cleanup, err
+	}
+	if err = app.WriteGenesisAccounts(path, accs); err != nil {
+		return cleanup, err
+	}
+	prev := viper.GetString(app.FlagGenesisAccounts)
+	undo = append(undo, func() { viper.Set(app.FlagGenesisAccounts, prev) })
+	viper.Set(app.FlagGenesisAccounts, path)
+	return cleanup, nil
+}
@@ -128,9 +128,15 @@ func initCmd(ctx *app.Context, cdc *amino.Codec, appInit app.AppInit) *cobra.Com
 				viper.GetBool(FlagOverwrite),
 				tmtime.Now(),
 			}
+			cleanup, err := generateGenesisKeys([]string{viper.GetString(FlagClientHome)})
+			if err != nil {
+				return err
+			}
 
 			chainID, nodeID, appMessage, err := InitWithConfig(cdc, appInit, config, initConfig)
 			if err != nil {
+				// the generated accounts are not funded by any genesis
+				cleanup()
 				return err
 			}
 			// print out some key information
@@ -156,6 +162,7 @@ func initCmd(ctx *app.Context, cdc *amino.Codec, appInit app.AppInit) *cobra.Com
 	cmd.Flags().Bool(FlagWithTxs, false, "apply existing genesis transactions from [--home]/config/gentx/")
 	cmd.Flags().AddFlagSet(appInit.FlagsAppGenState)
 	cmd.Flags().AddFlagSet(appInit.FlagsAppGenTx) // need to add this flagset for when no GenTx's provided
+	addGenesisKeysFlags(cmd)
 	cmd.AddCommand(GenTxCmd(ctx, cdc, appInit))
 	return cmd
 }
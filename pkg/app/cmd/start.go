@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	hnode "github.com/bluele/hypermint/pkg/node"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -13,6 +16,7 @@ import (
 	"github.com/tendermint/tendermint/proxy"
 
 	"github.com/bluele/hypermint/pkg/app"
+	"github.com/bluele/hypermint/pkg/contract"
 )
 
 const (
@@ -50,6 +54,10 @@ func startCmd(ctx *app.Context, appCreator app.AppCreator) *cobra.Command {
 	cmd.Flags().String(flagAddress, "tcp://0.0.0.0:26658", "Listen address")
 	cmd.Flags().String(flagTraceStore, "", "Enable KVStore tracing to an output file")
 	cmd.Flags().String(flagPruning, "syncable", "Pruning strategy: syncable, nothing, everything")
+	cmd.Flags().Bool(app.FlagContractDebug, false, "Return the debug info of contract execution in the simulation")
+	cmd.Flags().Bool(app.FlagContractDeliverLogs, false, "Return the logs of contracts in the result of delivered txs")
+	cmd.Flags().String(app.FlagContractEngine, contract.EngineLife, fmt.Sprintf("Engine which executes contracts. It must be the vm_engine of the genesis: %v", strings.Join(contract.Engines(), ", ")))
+	cmd.Flags().Uint64(app.FlagMinGasPrice, 0, "Min gas price of the txs which the node accepts into its mempool")
 
 	// add support for all Tendermint-specific command line options
 	tcmd.AddNodeFlags(cmd)
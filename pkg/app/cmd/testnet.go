@@ -62,7 +62,9 @@ Example:
 		"Number of non-validators to initialize the testnet with")
 	cmd.Flags().String(startingIPAddress, "192.168.0.1",
 		"Starting IP address (192.168.0.1 results in persistent peers list ID0@192.168.0.1:46656, ID1@192.168.0.2:46656, ...)")
+	cmd.Flags().AddFlagSet(appInit.FlagsAppGenState)
 	cmd.Flags().AddFlagSet(appInit.FlagsAppGenTx)
+	addGenesisKeysFlags(cmd)
 	return cmd
 }
 
@@ -117,6 +119,16 @@ func testnetWithConfig(c *cfg.Config, cdc *amino.Codec, appInit app.AppInit) err
 		}
 	}
 
+	// Generate the funded accounts into the client directories of validators
+	clientDirs := make([]string, numValidators)
+	for i := range clientDirs {
+		clientDirs[i] = getDirsInfo(outDir, i).ClientDir()
+	}
+	cleanup, err := generateGenesisKeys(clientDirs)
+	if err != nil {
+		return err
+	}
+
 	// Generate genesis.json and config.toml
 	chainID := "chain-" + cmn.RandStr(6)
 	genTime := tmtime.Now()
@@ -136,6 +148,7 @@ func testnetWithConfig(c *cfg.Config, cdc *amino.Codec, appInit app.AppInit) err
 		// Run `init` and generate genesis.json and config.toml
 		_, _, _, err := InitWithConfig(cdc, appInit, c, initConfig)
 		if err != nil {
+			cleanup()
 			return err
 		}
 		if i == 0 {
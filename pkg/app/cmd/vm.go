@@ -24,6 +24,7 @@ const (
 	flagArgs     = "args"
 	flagEntry    = "entry"
 	flagSimulate = "simulate"
+	flagDebug    = "debug"
 )
 
 func vmCmd(ctx *app.Context) *cobra.Command {
@@ -66,9 +67,13 @@ func vmCmd(ctx *app.Context) *cobra.Command {
 				Contract: ctr,
 				DB:       db.NewVersionedDB(kvs.Prefix(ctr.Address().Bytes())),
 				Args:     contract.NewArgsFromStrings(viper.GetStringSlice(flagArgs)),
+				Debug:    viper.GetBool(flagDebug),
 			}
 			c := sdk.NewContext(cms, abci.Header{}, false, nil)
 			res, err := env.Exec(c, viper.GetString(flagEntry))
+			if env.Debug {
+				pretty.Println(env.DebugInfo())
+			}
 			if err != nil {
 				return err
 			}
@@ -84,6 +89,7 @@ func vmCmd(ctx *app.Context) *cobra.Command {
 	cmd.Flags().String(flagEntry, "app_main", "")
 	cmd.Flags().String(helper.FlagAddress, "", "address")
 	cmd.Flags().Bool(flagSimulate, false, "is simluation")
+	cmd.Flags().Bool(flagDebug, false, "print the stack trace, host calls and logs")
 	util.CheckRequiredFlag(cmd, flagWASMPath)
 	return cmd
 }
@@ -17,9 +17,9 @@ type (
 	// application using various configurations.
 	AppCreator func(home string, logger log.Logger, traceStore string) (abci.Application, error)
 
-	// AppExporter reflects a function that dumps all app state to
+	// AppExporter reflects a function that dumps all app state at given height to
 	// JSON-serializable structure and returns the current validator set.
-	AppExporter func(home string, logger log.Logger, traceStore string) (json.RawMessage, []tmtypes.GenesisValidator, error)
+	AppExporter func(home string, logger log.Logger, traceStore string, height int64) (json.RawMessage, []tmtypes.GenesisValidator, error)
 
 	// AppCreatorInit reflects a function that performs initialization of an
 	// AppCreator.
@@ -27,7 +27,7 @@ type (
 
 	// AppExporterInit reflects a function that performs initialization of an
 	// AppExporter.
-	AppExporterInit func(log.Logger, dbm.DB, io.Writer) (json.RawMessage, []tmtypes.GenesisValidator, error)
+	AppExporterInit func(log.Logger, dbm.DB, io.Writer, int64) (json.RawMessage, []tmtypes.GenesisValidator, error)
 )
 
 // ConstructAppCreator returns an application generation function.
@@ -59,7 +59,7 @@ func ConstructAppCreator(appFn AppCreatorInit, name string) AppCreator {
 
 // ConstructAppExporter returns an application export function.
 func ConstructAppExporter(appFn AppExporterInit, name string) AppExporter {
-	return func(rootDir string, logger log.Logger, traceStore string) (json.RawMessage, []tmtypes.GenesisValidator, error) {
+	return func(rootDir string, logger log.Logger, traceStore string, height int64) (json.RawMessage, []tmtypes.GenesisValidator, error) {
 		dataDir := filepath.Join(rootDir, "data")
 
 		db, err := dbm.NewGoLevelDB(name, dataDir)
@@ -79,6 +79,6 @@ func ConstructAppExporter(appFn AppExporterInit, name string) AppExporter {
 			}
 		}
 
-		return appFn(logger, db, traceStoreWriter)
+		return appFn(logger, db, traceStoreWriter, height)
 	}
 }
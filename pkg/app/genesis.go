@@ -7,21 +7,45 @@ import (
 
 	"github.com/bluele/hypermint/pkg/abci/types"
 	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/contract"
+	"github.com/bluele/hypermint/pkg/db"
+	"github.com/bluele/hypermint/pkg/permission"
+	"github.com/bluele/hypermint/pkg/validator"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/viper"
 	"github.com/tendermint/go-amino"
 	abci "github.com/tendermint/tendermint/abci/types"
 )
 
 const (
+	// genesisBalance is the default balance of each address of the genesis transactions
 	genesisBalance = 100
+
+	// FlagFeeRecipient is the address which receives collected fees
+	FlagFeeRecipient = "fee-recipient"
 )
 
 // State to Unmarshal
 type GenesisState struct {
 	Accounts []account.Account `json:"accounts"`
+	// FeeRecipient receives collected fees. It is required because the proposer address of a block is
+	// a consensus address of tendermint, which is not an account that anyone controls.
+	FeeRecipient *common.Address `json:"fee_recipient,omitempty"`
+	// VMParams are the resource limits of contracts. If it is empty, contract.DefaultVMParams are used.
+	VMParams *contract.VMParams `json:"vm_params,omitempty"`
+	// VMEngine is the engine which executes contracts. Every node must run it. If it is empty, life is used.
+	VMEngine string `json:"vm_engine,omitempty"`
+	// Contract is the codes and contracts which the chain starts with
+	Contract *contract.GenesisState `json:"contract,omitempty"`
+	// Contracts are deployed after the state of Contract is imported
+	Contracts []GenesisContract `json:"contracts,omitempty"`
+	// ValidatorAdmins can update the validator set. If it is empty, the validator set is never updated.
+	ValidatorAdmins *validator.Admins `json:"validator_admins,omitempty"`
+	// Permission is the roles which restrict deploys and calls. If it is empty, the chain is permissionless.
+	Permission *permission.GenesisState `json:"permission,omitempty"`
 }
 
-func GetInitChainer(am account.AccountMapper) func(types.Context, abci.RequestInitChain) abci.ResponseInitChain {
+func GetInitChainer(am account.AccountMapper, cm *contract.ContractManager, envm *contract.EnvManager, sm *db.StateManager, vm validator.ValidatorMapper, pm permission.PermissionMapper) func(types.Context, abci.RequestInitChain) abci.ResponseInitChain {
 	return func(ctx types.Context, req abci.RequestInitChain) abci.ResponseInitChain {
 		stateJSON := req.AppStateBytes
 		// TODO is this now the whole genesis file?
@@ -37,18 +61,90 @@ func GetInitChainer(am account.AccountMapper) func(types.Context, abci.RequestIn
 			if _, err := am.AddBalance(ctx, acc.Address, acc.Amount); err != nil {
 				panic(err)
 			}
+			if acc.Nonce > 0 {
+				am.SetNonce(ctx, acc.Address, acc.Nonce)
+			}
 			fmt.Printf("addr=%v amount=%v\n",
 				acc.Address.Hex(),
 				acc.Amount,
 			)
 
 		}
+		if genesisState.FeeRecipient == nil {
+			panic(errors.New("fee_recipient is required"))
+		}
+		am.SetFeeRecipient(ctx, *genesisState.FeeRecipient)
+		if genesisState.VMParams != nil {
+			if err := envm.SetParams(ctx, *genesisState.VMParams); err != nil {
+				panic(err)
+			}
+		}
+		if err := envm.SetChainEngine(ctx, genesisState.VMEngine); err != nil {
+			panic(err)
+		}
+		if genesisState.Contract != nil {
+			if err := envm.ImportGenesis(ctx, *genesisState.Contract); err != nil {
+				panic(err)
+			}
+		}
+		// the validators of the genesis are sent by tendermint
+		for _, vu := range req.Validators {
+			v, err := validator.ValidatorFromABCI(vu)
+			if err != nil {
+				panic(err)
+			}
+			vm.SetValidator(ctx, v)
+		}
+		if genesisState.ValidatorAdmins != nil {
+			if err := vm.SetAdmins(ctx, *genesisState.ValidatorAdmins); err != nil {
+				panic(err)
+			}
+		}
+		if genesisState.Permission != nil {
+			if err := permission.ImportGenesis(ctx, pm, *genesisState.Permission); err != nil {
+				panic(err)
+			}
+		}
+		for i, gc := range genesisState.Contracts {
+			// each deployment is versioned like a tx in the genesis block
+			addr, err := deployGenesisContract(ctx.WithTxIndex(uint32(i)), am, cm, envm, sm, gc)
+			if err != nil {
+				panic(fmt.Errorf("failed to deploy genesis contract %v: %v", i, err))
+			}
+			fmt.Printf("contract=%v owner=%v\n",
+				addr.Hex(),
+				gc.Owner.Hex(),
+			)
+		}
 
 		// load the initial stake information
 		return abci.ResponseInitChain{}
 	}
 }
 
+// ExportGenesisState exports the state of the chain which GetInitChainer can import
+func ExportGenesisState(ctx types.Context, am account.AccountMapper, envm *contract.EnvManager, vm validator.ValidatorMapper, pm permission.PermissionMapper) (*GenesisState, error) {
+	accs, err := am.Accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gs := &GenesisState{Accounts: accs}
+	if addr, ok := am.GetFeeRecipient(ctx); ok {
+		gs.FeeRecipient = &addr
+	}
+	params := envm.GetParams(ctx)
+	gs.VMParams = &params
+	gs.VMEngine = envm.GetChainEngine(ctx)
+	if gs.Contract, err = envm.ExportGenesis(ctx); err != nil {
+		return nil, err
+	}
+	if admins, ok := vm.GetAdmins(ctx); ok {
+		gs.ValidatorAdmins = &admins
+	}
+	gs.Permission = permission.ExportGenesis(ctx, pm)
+	return gs, nil
+}
+
 // Create the core parameters for genesis initialization
 // note that the pubkey input is this machines pubkey
 func AppGenState(cdc *amino.Codec, appGenTxs []json.RawMessage) (genesisState GenesisState, err error) {
@@ -57,6 +153,17 @@ func AppGenState(cdc *amino.Codec, appGenTxs []json.RawMessage) (genesisState Ge
 		return
 	}
 
+	var balance uint64 = genesisBalance
+	if viper.IsSet(FlagGenesisBalance) {
+		if balance, err = getUint64(FlagGenesisBalance); err != nil {
+			return
+		}
+	}
+	expectedSupply, err := getUint64(FlagTotalSupply)
+	if err != nil {
+		return
+	}
+
 	// get genesis flag account information
 	accounts := make([]account.Account, 0, len(appGenTxs))
 	accountm := make(map[common.Address]struct{})
@@ -72,15 +179,47 @@ func AppGenState(cdc *amino.Codec, appGenTxs []json.RawMessage) (genesisState Ge
 		if _, ok := accountm[addr]; !ok && genTx.Address != "" {
 			accounts = append(accounts, account.Account{
 				Address: addr,
-				Amount:  genesisBalance,
+				Amount:  balance,
 			})
 			accountm[addr] = struct{}{}
 		}
 	}
+	var feeRecipient common.Address
+	if s := viper.GetString(FlagFeeRecipient); s != "" {
+		if !common.IsHexAddress(s) {
+			err = fmt.Errorf("invalid %v: %v", FlagFeeRecipient, s)
+			return
+		}
+		feeRecipient = common.HexToAddress(s)
+	} else if len(accounts) > 0 {
+		feeRecipient = accounts[0].Address
+	} else {
+		err = fmt.Errorf("%v is required because the genesis transactions have no address", FlagFeeRecipient)
+		return
+	}
+
+	var fileAccounts []account.Account
+	if path := viper.GetString(FlagGenesisAccounts); path != "" {
+		if fileAccounts, err = ReadGenesisAccounts(path); err != nil {
+			return
+		}
+	}
+	accounts, supply, err := mergeAccounts(accounts, fileAccounts)
+	if err != nil {
+		return
+	}
+	if expectedSupply > 0 && supply != expectedSupply {
+		err = fmt.Errorf("total supply of the genesis is %v, but %v is expected", supply, expectedSupply)
+		return
+	}
 
 	// create the final app state
 	genesisState = GenesisState{
-		Accounts: accounts,
+		Accounts:     accounts,
+		FeeRecipient: &feeRecipient,
+	}
+	if path := viper.GetString(flagGenesisContracts); path != "" {
+		genesisState.Contracts, err = ReadGenesisContracts(path)
 	}
 	return
 }
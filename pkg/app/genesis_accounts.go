@@ -0,0 +1,100 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/bluele/hypermint/pkg/account"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/viper"
+)
+
+const (
+	// FlagGenesisAccounts is the JSON file which maps addresses to their genesis balances
+	FlagGenesisAccounts = "genesis-accounts"
+	// FlagGenesisBalance is the genesis balance of each address of the genesis transactions
+	FlagGenesisBalance = "genesis-balance"
+	// FlagTotalSupply is the expected total of the genesis balances. 0 disables the check.
+	FlagTotalSupply = "total-supply"
+)
+
+// ReadGenesisAccounts reads the accounts from the file which maps addresses to their balances.
+// The accounts are sorted by their addresses.
+func ReadGenesisAccounts(path string) ([]account.Account, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var balances map[common.Address]uint64
+	if err := json.Unmarshal(b, &balances); err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %v", path, err)
+	}
+	accs := make([]account.Account, 0, len(balances))
+	for addr, amount := range balances {
+		accs = append(accs, account.Account{Address: addr, Amount: amount})
+	}
+	sort.Slice(accs, func(i, j int) bool {
+		return bytes.Compare(accs[i].Address.Bytes(), accs[j].Address.Bytes()) < 0
+	})
+	return accs, nil
+}
+
+// WriteGenesisAccounts writes the balances of the accounts to the file which ReadGenesisAccounts reads
+func WriteGenesisAccounts(path string, accs []account.Account) error {
+	accs, _, err := mergeAccounts(accs)
+	if err != nil {
+		return err
+	}
+	balances := make(map[common.Address]uint64, len(accs))
+	for _, acc := range accs {
+		balances[acc.Address] = acc.Amount
+	}
+	b, err := json.MarshalIndent(balances, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// mergeAccounts sums the balances of the same address, and returns the merged accounts and the total supply.
+// The accounts keep the order in which they first appear.
+func mergeAccounts(accs ...[]account.Account) ([]account.Account, uint64, error) {
+	var (
+		merged []account.Account
+		supply uint64
+	)
+	idx := make(map[common.Address]int)
+	for _, as := range accs {
+		for _, acc := range as {
+			if supply > math.MaxUint64-acc.Amount {
+				return nil, 0, fmt.Errorf("total supply overflows: address=%v amount=%v", acc.Address.Hex(), acc.Amount)
+			}
+			supply += acc.Amount
+			if i, ok := idx[acc.Address]; ok {
+				merged[i].Amount += acc.Amount
+				continue
+			}
+			idx[acc.Address] = len(merged)
+			merged = append(merged, acc)
+		}
+	}
+	return merged, supply, nil
+}
+
+// getUint64 returns the value of the flag as uint64. An empty value means 0.
+func getUint64(key string) (uint64, error) {
+	s := viper.GetString(key)
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v: %v", key, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/contract"
+	"github.com/bluele/hypermint/pkg/db"
+	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GenesisContract is a contract which is deployed at the genesis
+type GenesisContract struct {
+	// Code is the wasm module, which is encoded in base64
+	Code []byte `json:"code,omitempty"`
+	// CodeFile is the path of the wasm module. It is relative to the file which contains it.
+	// `hmd init` embeds the code into the genesis, so the genesis doesn't contain CodeFile.
+	CodeFile string `json:"code_file,omitempty"`
+	// Owner is the owner of the contract. The address of the contract is derived from the owner and its nonce.
+	Owner common.Address `json:"owner"`
+	// Args are the arguments of the init function, which are encoded in base64.
+	// If the code doesn't export the init function, Args must be empty.
+	Args [][]byte `json:"args,omitempty"`
+	// State is the initial state of the contract, which the init function can read
+	State []GenesisKV `json:"state,omitempty"`
+}
+
+// GenesisKV is a key-value pair in the state of a contract. The key and the value are encoded in base64.
+type GenesisKV struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// ReadGenesisContracts reads the contracts from the file, and embeds their code files
+func ReadGenesisContracts(path string) ([]GenesisContract, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var gcs []GenesisContract
+	if err := json.Unmarshal(b, &gcs); err != nil {
+		return nil, fmt.Errorf("failed to parse %v: %v", path, err)
+	}
+	for i, gc := range gcs {
+		if gc.CodeFile == "" {
+			continue
+		}
+		if len(gc.Code) > 0 {
+			return nil, fmt.Errorf("contract %v has both code and code_file", i)
+		}
+		codePath := gc.CodeFile
+		if !filepath.IsAbs(codePath) {
+			codePath = filepath.Join(filepath.Dir(path), codePath)
+		}
+		if gcs[i].Code, err = ioutil.ReadFile(codePath); err != nil {
+			return nil, err
+		}
+		gcs[i].CodeFile = ""
+	}
+	return gcs, nil
+}
+
+// deployGenesisContract deploys the contract like ContractInstantiateTx from the owner, and returns its address
+func deployGenesisContract(ctx types.Context, am account.AccountMapper, cm *contract.ContractManager, envm *contract.EnvManager, sm *db.StateManager, gc GenesisContract) (common.Address, error) {
+	if len(gc.Code) == 0 {
+		return common.Address{}, errors.New("code is empty")
+	}
+	h, err := cm.UploadCode(ctx, gc.Code)
+	if err != nil {
+		return common.Address{}, err
+	}
+	nonce, err := am.GetNonce(ctx, gc.Owner)
+	if err != nil {
+		return common.Address{}, err
+	}
+	args := contract.NewArgs(gc.Args)
+	addr, err := cm.InstantiateContract(ctx, &transaction.ContractInstantiateTx{
+		Common:   transaction.CommonTx{From: gc.Owner, Nonce: nonce},
+		CodeHash: h,
+	})
+	if err != nil {
+		return addr, err
+	}
+	if _, err := am.IncrNonce(ctx, gc.Owner); err != nil {
+		return addr, err
+	}
+
+	state := make([]db.KV, len(gc.State))
+	for i, kv := range gc.State {
+		state[i] = db.KV{Key: kv.Key, Value: kv.Value}
+	}
+	envm.InitState(ctx, addr, state)
+
+	env, err := envm.Get(ctx, gc.Owner, addr, args)
+	if err != nil {
+		return addr, err
+	}
+	res, err := env.Exec(ctx, transaction.ContractInitFunc)
+	if err == contract.ErrEntryNotFound && len(gc.Args) == 0 {
+		return addr, nil
+	} else if err != nil {
+		return addr, err
+	}
+	return addr, sm.CommitState(ctx, res.State.RWSets())
+}
@@ -0,0 +1,282 @@
+package app
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/config"
+	"github.com/bluele/hypermint/pkg/contract"
+	"github.com/bluele/hypermint/pkg/db"
+	"github.com/bluele/hypermint/pkg/permission"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/tendermint/tendermint/libs/log"
+	tmtypes "github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+)
+
+const testChainID = "test-chain"
+
+// initChain creates a chain from the app state and the validators, and commits the first block
+func initChain(t *testing.T, appState json.RawMessage, validators []abci.ValidatorUpdate) (*Chain, []byte) {
+	c := NewChain(log.NewNopLogger(), dbm.NewMemDB(), nil)
+	c.InitChain(abci.RequestInitChain{ChainId: testChainID, AppStateBytes: appState, Validators: validators})
+	return c, commitBlock(c, 1, nil)
+}
+
+// commitBlock commits a block in which f updates the state, and returns the app hash
+func commitBlock(c *Chain, height int64, f func(ctx sdk.Context)) []byte {
+	header := abci.Header{ChainID: testChainID, Height: height}
+	c.BeginBlock(abci.RequestBeginBlock{Header: header})
+	if f != nil {
+		f(c.NewContext(false, header))
+	}
+	c.EndBlock(abci.RequestEndBlock{Height: height})
+	return c.Commit().Data
+}
+
+func exportState(t *testing.T, c *Chain, height int64) (json.RawMessage, []tmtypes.GenesisValidator) {
+	appState, validators, err := c.ExportAppStateJSON(height)
+	require.NoError(t, err)
+	return appState, validators
+}
+
+// storeContents returns all the key-value pairs of the committed stores of the chain
+func storeContents(c *Chain) map[string]string {
+	ctx := c.NewContext(true, abci.Header{})
+	kvs := make(map[string]string)
+	for _, key := range []sdk.StoreKey{c.capKeyMainStore, c.contractStore, c.validatorStore, c.permissionStore} {
+		it := ctx.KVStore(key).Iterator(nil, nil)
+		for ; it.Valid(); it.Next() {
+			kvs[key.Name()+"/"+string(it.Key())] = string(it.Value())
+		}
+		it.Close()
+	}
+	return kvs
+}
+
+func TestExportGenesis(t *testing.T) {
+	alice := common.BytesToAddress([]byte("alice"))
+	bob := common.BytesToAddress([]byte("bob"))
+	code, err := hex.DecodeString(testCodeInit)
+	require.NoError(t, err)
+	caddr := common.BytesToAddress([]byte("contract"))
+	params := contract.DefaultVMParams()
+	params.MaxEvents = 10
+
+	gs := GenesisState{
+		Accounts:     []account.Account{{Address: alice, Amount: 100}},
+		FeeRecipient: &bob,
+		VMParams:     &params,
+		Contract: &contract.GenesisState{
+			Codes: []hexutil.Bytes{code},
+			Contracts: []contract.GenesisContract{{
+				Address:  caddr,
+				Owner:    alice,
+				CodeHash: contract.CodeHash(code),
+				State: []contract.GenesisValue{
+					{Key: []byte("key"), Value: []byte("value"), Version: db.Version{Height: 1, TxIdx: 2}},
+				},
+			}},
+		},
+		Permission: &permission.GenesisState{
+			Admins:          []common.Address{alice},
+			Deployers:       []common.Address{bob},
+			ContractCallers: []permission.ContractCallers{{Contract: caddr, Callers: []common.Address{bob}}},
+		},
+	}
+	appState, err := json.Marshal(gs)
+	require.NoError(t, err)
+	pub := secp256k1.GenPrivKey().PubKey()
+	validators := []abci.ValidatorUpdate{tmtypes.TM2PB.NewValidatorUpdate(pub, 10)}
+
+	a, _ := initChain(t, appState, validators)
+	commitBlock(a, 2, func(ctx sdk.Context) {
+		_, err := a.am.AddBalance(ctx, bob, 10)
+		require.NoError(t, err)
+		_, err = a.am.IncrNonce(ctx, alice)
+		require.NoError(t, err)
+	})
+
+	// the state at height 2
+	exported, evs := exportState(t, a, 0)
+	assert.Equal(t, []tmtypes.GenesisValidator{{Address: pub.Address(), PubKey: pub, Power: 10}}, evs)
+	var egs GenesisState
+	require.NoError(t, json.Unmarshal(exported, &egs))
+	assert.Equal(t, []account.Account{
+		{Address: alice, Amount: 100, Nonce: 1},
+		{Address: bob, Amount: 10},
+	}, sortedAccounts(egs.Accounts, alice, bob))
+	assert.Equal(t, bob, *egs.FeeRecipient)
+	assert.Equal(t, params, *egs.VMParams)
+	assert.Equal(t, gs.Contract, egs.Contract)
+	assert.Equal(t, gs.Permission, egs.Permission)
+
+	// the chain which starts with the exported state has the same state as the original one
+	b, hashB := initChain(t, exported, validatorUpdates(evs))
+	assert.Equal(t, storeContents(a), storeContents(b))
+	exportedB, evsB := exportState(t, b, 0)
+	assert.Equal(t, string(exported), string(exportedB))
+	assert.Equal(t, evs, evsB)
+	// and the chains which start with the same exported state have the same app hash
+	_, hashC := initChain(t, exportedB, validatorUpdates(evsB))
+	assert.Equal(t, hashB, hashC)
+
+	// the state at height 1 doesn't include the updates at height 2
+	var gs1 GenesisState
+	exported1, _ := exportState(t, a, 1)
+	require.NoError(t, json.Unmarshal(exported1, &gs1))
+	assert.Equal(t, gs.Accounts, gs1.Accounts)
+}
+
+func validatorUpdates(vs []tmtypes.GenesisValidator) []abci.ValidatorUpdate {
+	var vus []abci.ValidatorUpdate
+	for _, v := range vs {
+		vus = append(vus, tmtypes.TM2PB.NewValidatorUpdate(v.PubKey, v.Power))
+	}
+	return vus
+}
+
+func sortedAccounts(accs []account.Account, addrs ...common.Address) []account.Account {
+	var ret []account.Account
+	for _, addr := range addrs {
+		for _, acc := range accs {
+			if acc.Address == addr {
+				ret = append(ret, acc)
+			}
+		}
+	}
+	return ret
+}
+
+// testCodeInit is a wasm module whose init function writes the first argument to "key"
+const testCodeInit = "0061736d01000000010d0260047f7f7f7f017f6000017f02250203656e76095f5f6765745f617267000003656e760d5f5f77726974655f7374617465000003020101050301000107080104696e697400020a20011e01017f41004100411041c00010002100410041034110200010011a41000b0b09010041000b036b6579"
+
+func TestGenesisContracts(t *testing.T) {
+	alice := common.BytesToAddress([]byte("alice"))
+	code, err := hex.DecodeString(testCodeInit)
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "genesis")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "init.wasm"), code, 0644))
+	path := filepath.Join(dir, "contracts.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`[{
+		"code_file": "init.wasm",
+		"owner": "`+alice.Hex()+`",
+		"args": ["aGVsbG8="],
+		"state": [{"key": "c2VlZA==", "value": "MQ=="}]
+	}]`), 0644))
+
+	gcs, err := ReadGenesisContracts(path)
+	require.NoError(t, err)
+	require.Len(t, gcs, 1)
+	assert.Equal(t, code, gcs[0].Code)
+	assert.Empty(t, gcs[0].CodeFile)
+
+	appState, err := json.Marshal(GenesisState{FeeRecipient: &alice, Contracts: gcs})
+	require.NoError(t, err)
+	c, _ := initChain(t, appState, nil)
+
+	var gs GenesisState
+	exported, _ := exportState(t, c, 0)
+	require.NoError(t, json.Unmarshal(exported, &gs))
+	// the owner uses a nonce to deploy the contract like ContractInstantiateTx
+	assert.Equal(t, []account.Account{{Address: alice, Nonce: 1}}, gs.Accounts)
+	require.Len(t, gs.Contract.Contracts, 1)
+	gc := gs.Contract.Contracts[0]
+	assert.Equal(t, contract.NewContractAddress(alice, 0), gc.Address)
+	assert.Equal(t, alice, gc.Owner)
+	assert.Equal(t, contract.CodeHash(code), gc.CodeHash)
+	assert.Equal(t, []contract.GenesisValue{
+		{Key: []byte("key"), Value: []byte("hello")},
+		{Key: []byte("seed"), Value: []byte("1")},
+	}, gc.State)
+
+	gcs[0].Code = []byte("invalid")
+	appState, err = json.Marshal(GenesisState{FeeRecipient: &alice, Contracts: gcs})
+	require.NoError(t, err)
+	assert.Panics(t, func() { initChain(t, appState, nil) })
+}
+
+func TestGenesisEngine(t *testing.T) {
+	alice := common.BytesToAddress([]byte("alice"))
+	appState, err := json.Marshal(GenesisState{FeeRecipient: &alice, VMEngine: contract.EngineInterp})
+	require.NoError(t, err)
+
+	// the node runs life by default
+	assert.Panics(t, func() { initChain(t, appState, nil) })
+
+	viper.Set(FlagContractEngine, contract.EngineInterp)
+	defer viper.Set(FlagContractEngine, nil)
+	c, _ := initChain(t, appState, nil)
+	var gs GenesisState
+	exported, _ := exportState(t, c, 0)
+	require.NoError(t, json.Unmarshal(exported, &gs))
+	assert.Equal(t, contract.EngineInterp, gs.VMEngine)
+
+	// the genesis without vm_engine runs on life
+	appState, err = json.Marshal(GenesisState{FeeRecipient: &alice})
+	require.NoError(t, err)
+	assert.Panics(t, func() { initChain(t, appState, nil) })
+}
+
+func TestAppGenState(t *testing.T) {
+	alice := common.BytesToAddress([]byte("alice"))
+	bob := common.BytesToAddress([]byte("bob"))
+
+	dir, err := ioutil.TempDir("", "genesis")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "accounts.json")
+	require.NoError(t, WriteGenesisAccounts(path, []account.Account{{Address: bob, Amount: 20}, {Address: alice, Amount: 5}, {Address: bob, Amount: 30}}))
+	accs, err := ReadGenesisAccounts(path)
+	require.NoError(t, err)
+	assert.Equal(t, []account.Account{{Address: bob, Amount: 50}, {Address: alice, Amount: 5}}, accs)
+
+	appGenTx, _, _, err := CreateAppGenTxNF(cdc, nil, alice.Hex(), config.GenTx{})
+	require.NoError(t, err)
+	var genState = func(flags map[string]string) (GenesisState, error) {
+		for k, v := range flags {
+			viper.Set(k, v)
+			defer viper.Set(k, nil)
+		}
+		return AppGenState(cdc, []json.RawMessage{appGenTx})
+	}
+
+	gs, err := genState(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []account.Account{{Address: alice, Amount: genesisBalance}}, gs.Accounts)
+	// the address of the genesis transaction receives fees by default
+	assert.Equal(t, alice, *gs.FeeRecipient)
+
+	gs, err = genState(map[string]string{FlagFeeRecipient: bob.Hex()})
+	require.NoError(t, err)
+	assert.Equal(t, bob, *gs.FeeRecipient)
+	_, err = genState(map[string]string{FlagFeeRecipient: "bob"})
+	assert.Error(t, err)
+
+	gs, err = genState(map[string]string{FlagGenesisAccounts: path, FlagGenesisBalance: "10", FlagTotalSupply: "65"})
+	require.NoError(t, err)
+	assert.Equal(t, []account.Account{{Address: alice, Amount: 15}, {Address: bob, Amount: 50}}, gs.Accounts)
+
+	_, err = genState(map[string]string{FlagGenesisAccounts: path, FlagTotalSupply: "65"})
+	assert.Error(t, err)
+
+	require.NoError(t, WriteGenesisAccounts(path, []account.Account{{Address: bob, Amount: math.MaxUint64}}))
+	_, err = genState(map[string]string{FlagGenesisAccounts: path})
+	assert.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const (
+	flagGas      = "gas"
+	flagGasPrice = "gas-price"
+	flagTarget   = "target"
+	flagRole     = "role"
+	flagContract = "contract"
+)
+
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "inspect and modify the roles of accounts",
+}
+
+func Setup(cmd *cobra.Command) {
+	cmd.AddCommand(adminCmd)
+}
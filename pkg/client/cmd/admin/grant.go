@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/client"
+	"github.com/bluele/hypermint/pkg/client/helper"
+	"github.com/bluele/hypermint/pkg/permission"
+	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/bluele/hypermint/pkg/util"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{grantCmd, revokeCmd} {
+		adminCmd.AddCommand(cmd)
+		cmd.Flags().String(helper.FlagAddress, "", "address of the admin")
+		cmd.Flags().StringSlice(flagTarget, nil, "addresses whose roles are updated")
+		cmd.Flags().String(flagRole, "", "role: admin, deployer or caller")
+		cmd.Flags().String(flagContract, "", "contract address which the caller role is limited to")
+		cmd.Flags().Uint(flagGas, 0, "gas for tx")
+		cmd.Flags().Uint(flagGasPrice, 0, "gas price for tx")
+		util.CheckRequiredFlag(cmd, helper.FlagAddress, flagTarget, flagRole, flagGas)
+	}
+}
+
+var grantCmd = &cobra.Command{
+	Use:   "grant",
+	Short: "grant a role to accounts",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return updateRoles(cmd, false)
+	},
+}
+
+var revokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "revoke a role from accounts",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return updateRoles(cmd, true)
+	},
+}
+
+func updateRoles(cmd *cobra.Command, revoke bool) error {
+	viper.BindPFlags(cmd.Flags())
+	ctx, err := client.NewClientContextFromViper()
+	if err != nil {
+		return err
+	}
+	addrs, err := ctx.GetInputAddresses()
+	if err != nil {
+		return err
+	}
+	from := addrs[0]
+
+	role, err := permission.ParseRole(viper.GetString(flagRole))
+	if err != nil {
+		return err
+	}
+	var contract common.Address
+	if s := viper.GetString(flagContract); s != "" {
+		if contract, err = helper.StrToAddress(s); err != nil {
+			return err
+		}
+	}
+	var updates []transaction.RoleUpdate
+	for _, target := range viper.GetStringSlice(flagTarget) {
+		addr, err := helper.StrToAddress(target)
+		if err != nil {
+			return err
+		}
+		updates = append(updates, transaction.RoleUpdate{
+			Address:  addr,
+			Role:     uint8(role),
+			Contract: contract,
+			Revoke:   revoke,
+		})
+	}
+
+	nonce, err := ctx.GetNonceByAddress(from)
+	if err != nil {
+		return err
+	}
+	tx := &transaction.AdminTx{
+		Common: transaction.CommonTx{
+			Code:     transaction.ADMIN,
+			From:     from,
+			Gas:      uint64(viper.GetInt(flagGas)),
+			GasPrice: uint64(viper.GetInt(flagGasPrice)),
+			Nonce:    nonce,
+		},
+		Updates: updates,
+	}
+	if err := ctx.SignAndBroadcastTx(tx, from); err != nil {
+		return err
+	}
+	fmt.Println("ok")
+	return nil
+}
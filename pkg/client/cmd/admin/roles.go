@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/client"
+	"github.com/bluele/hypermint/pkg/client/helper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	adminCmd.AddCommand(rolesCmd)
+	rolesCmd.Flags().String(flagTarget, "", "address whose roles are shown. if not specified, all the roles are shown")
+}
+
+var rolesCmd = &cobra.Command{
+	Use:   "roles",
+	Short: "show the roles of accounts",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		viper.BindPFlags(cmd.Flags())
+		ctx, err := client.NewClientContextFromViper()
+		if err != nil {
+			return err
+		}
+		var v interface{}
+		if target := viper.GetString(flagTarget); target != "" {
+			addr, err := helper.StrToAddress(target)
+			if err != nil {
+				return err
+			}
+			if v, err = ctx.GetRoles(addr); err != nil {
+				return err
+			}
+		} else {
+			gs, err := ctx.GetPermissions()
+			if err != nil {
+				return err
+			}
+			if gs == nil {
+				fmt.Println("permissions are not enabled")
+				return nil
+			}
+			v = gs
+		}
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	},
+}
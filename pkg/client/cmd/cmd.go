@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 
+	"github.com/bluele/hypermint/pkg/client/cmd/admin"
 	"github.com/bluele/hypermint/pkg/client/cmd/contract"
 	"github.com/bluele/hypermint/pkg/client/helper"
 	"github.com/spf13/cobra"
@@ -28,5 +29,6 @@ func init() {
 	rootCmd.PersistentFlags().String(helper.FlagNode, "tcp://localhost:26657", "<host>:<port> to tendermint rpc interface for this chain")
 	rootCmd.PersistentFlags().StringP(helper.FlagPassword, "p", "", "password for signing tx")
 	contract.Setup(rootCmd)
+	admin.Setup(rootCmd)
 	viper.BindPFlags(rootCmd.Flags())
 }
@@ -28,6 +28,7 @@ const (
 	flagArgTypes        = "argtypes"
 	flagReturnValueType = "type"
 	flagSilent          = "silent"
+	flagAmount          = "amount"
 )
 
 func init() {
@@ -35,10 +36,12 @@ func init() {
 	callCmd.Flags().String(helper.FlagAddress, "", "address")
 	callCmd.Flags().String(flagContract, "", "contract address")
 	callCmd.Flags().String(flagFunc, "", "function name")
+	callCmd.Flags().Uint(flagAmount, 0, "amount of coin which is sent to the contract")
 	callCmd.Flags().StringSlice(flagArgs, nil, "arguments")
 	callCmd.Flags().StringSlice(flagArgTypes, nil, "types of arguments")
-	callCmd.Flags().String(flagRWSetsHash, "", "RWSets hash")
+	callCmd.Flags().String(flagRWSetsHash, "", "RWSets hash which the execution must produce. It doesn't cover the balances of accounts")
 	callCmd.Flags().Uint(flagGas, 0, "gas for tx")
+	callCmd.Flags().Uint(flagGasPrice, 0, "gas price for tx")
 	callCmd.Flags().String(flagReturnValueType, contract.Int, "a type of return value")
 	callCmd.Flags().Bool(flagSimulate, false, "execute as simulation")
 	callCmd.Flags().Bool(flagSilent, false, "if true, suppress unnecessary output")
@@ -59,7 +62,7 @@ var callCmd = &cobra.Command{
 			return err
 		}
 		from := addrs[0]
-		nonce, err := transaction.GetNonceByAddress(from)
+		nonce, err := ctx.GetNonceByAddress(from)
 		if err != nil {
 			return err
 		}
@@ -82,19 +85,29 @@ var callCmd = &cobra.Command{
 		}
 		tx := &transaction.ContractCallTx{
 			Address:    caddr,
+			Amount:     uint64(viper.GetInt(flagAmount)),
 			Func:       viper.GetString(flagFunc),
 			Args:       args,
 			RWSetsHash: rwh,
 			Common: transaction.CommonTx{
-				Code:  transaction.CONTRACT_CALL,
-				From:  from,
-				Gas:   uint64(viper.GetInt(flagGas)),
-				Nonce: nonce,
+				Code:     transaction.CONTRACT_CALL,
+				From:     from,
+				Gas:      uint64(viper.GetInt(flagGas)),
+				GasPrice: uint64(viper.GetInt(flagGasPrice)),
+				Nonce:    nonce,
 			},
 		}
 		if viper.GetBool(flagSimulate) {
 			r, err := ctx.SignAndSimulateTx(tx, from)
 			if err != nil {
+				// the node returns the logs and the debug info of failed execution
+				res := new(handler.ContractCallTxResponse)
+				if len(r) > 0 && amino.UnmarshalBinaryBare(r, res) == nil {
+					printLogs(res.Logs)
+					if res.Debug != nil {
+						pretty.Println(res.Debug)
+					}
+				}
 				return err
 			}
 			res := new(handler.ContractCallTxResponse)
@@ -109,6 +122,10 @@ var callCmd = &cobra.Command{
 				fmt.Print(hex.EncodeToString(res.Returned))
 			} else {
 				pretty.Println(rs)
+				printLogs(res.Logs)
+				if res.Debug != nil {
+					pretty.Println(res.Debug)
+				}
 				fmt.Printf("RWSetsHash: 0x%x\n", rs.Hash())
 				v, err := contract.DeserializeValue(res.Returned, viper.GetString(flagReturnValueType))
 				if err != nil {
@@ -126,3 +143,9 @@ var callCmd = &cobra.Command{
 		return nil
 	},
 }
+
+func printLogs(logs []string) {
+	for _, l := range logs {
+		fmt.Println("Log:", l)
+	}
+}
@@ -5,7 +5,8 @@ import (
 )
 
 const (
-	flagGas = "gas"
+	flagGas      = "gas"
+	flagGasPrice = "gas-price"
 )
 
 var contractCmd = &cobra.Command{
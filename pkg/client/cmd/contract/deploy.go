@@ -6,29 +6,38 @@ import (
 	"os"
 
 	"github.com/bluele/hypermint/pkg/client"
+	clicontract "github.com/bluele/hypermint/pkg/client/contract"
 	"github.com/bluele/hypermint/pkg/client/helper"
 	"github.com/bluele/hypermint/pkg/contract"
 	"github.com/bluele/hypermint/pkg/transaction"
 	"github.com/bluele/hypermint/pkg/util"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 const (
-	flagCode = "path"
+	flagCode       = "path"
+	flagCodeHash   = "code-hash"
+	flagUploadOnly = "upload-only"
 )
 
 func init() {
 	contractCmd.AddCommand(deployCmd)
 	deployCmd.Flags().String(helper.FlagAddress, "", "address")
 	deployCmd.Flags().String(flagCode, "", "contract code path")
+	deployCmd.Flags().String(flagCodeHash, "", "hash of the uploaded code. if specified, upload step is skipped")
+	deployCmd.Flags().Bool(flagUploadOnly, false, "if true, only upload the code and print its hash")
+	deployCmd.Flags().StringSlice(flagArgs, nil, "arguments for init function")
+	deployCmd.Flags().StringSlice(flagArgTypes, nil, "types of arguments")
 	deployCmd.Flags().Uint(flagGas, 0, "gas for tx")
-	util.CheckRequiredFlag(deployCmd, helper.FlagAddress, flagCode, flagGas)
+	deployCmd.Flags().Uint(flagGasPrice, 0, "gas price for tx")
+	util.CheckRequiredFlag(deployCmd, helper.FlagAddress, flagGas)
 }
 
 var deployCmd = &cobra.Command{
 	Use:   "deploy",
-	Short: "deploy contract code",
+	Short: "upload contract code and instantiate a contract",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		viper.BindPFlags(cmd.Flags())
 		ctx, err := client.NewClientContextFromViper()
@@ -36,35 +45,69 @@ var deployCmd = &cobra.Command{
 			return err
 		}
 
-		path := viper.GetString(flagCode)
-		code, err := getCode(path)
+		addrs, err := ctx.GetInputAddresses()
 		if err != nil {
 			return err
 		}
+		from := addrs[0]
 
-		addrs, err := ctx.GetInputAddresses()
+		nonce, err := ctx.GetNonceByAddress(from)
 		if err != nil {
 			return err
 		}
-		from := addrs[0]
+		var newCommon = func(code uint8) transaction.CommonTx {
+			return transaction.CommonTx{
+				Code:     code,
+				From:     from,
+				Gas:      uint64(viper.GetInt(flagGas)),
+				GasPrice: uint64(viper.GetInt(flagGasPrice)),
+				Nonce:    nonce,
+			}
+		}
+
+		var codeHash common.Hash
+		if h := viper.GetString(flagCodeHash); h != "" {
+			codeHash = common.HexToHash(h)
+		} else {
+			path := viper.GetString(flagCode)
+			if path == "" {
+				return fmt.Errorf("either --%v or --%v is required", flagCode, flagCodeHash)
+			}
+			code, err := getCode(path)
+			if err != nil {
+				return err
+			}
+			tx := &transaction.ContractDeployTx{
+				Code:   code,
+				Common: newCommon(transaction.CONTRACT_DEPLOY),
+			}
+			if err := ctx.SignAndBroadcastTx(tx, from); err != nil {
+				return err
+			}
+			codeHash = contract.CodeHash(code)
+			if viper.GetBool(flagUploadOnly) {
+				fmt.Print(codeHash.Hex())
+				return nil
+			}
+			nonce++
+		}
 
-		nonce, err := transaction.GetNonceByAddress(from)
+		initArgs, err := clicontract.SerializeCallArgs(
+			viper.GetStringSlice(flagArgs),
+			viper.GetStringSlice(flagArgTypes),
+		)
 		if err != nil {
 			return err
 		}
-		tx := &transaction.ContractDeployTx{
-			Code: code,
-			Common: transaction.CommonTx{
-				Code:  transaction.CONTRACT_DEPLOY,
-				From:  from,
-				Gas:   uint64(viper.GetInt(flagGas)),
-				Nonce: nonce,
-			},
+		tx := &transaction.ContractInstantiateTx{
+			CodeHash: codeHash,
+			Args:     initArgs,
+			Common:   newCommon(transaction.CONTRACT_INSTANTIATE),
 		}
 		if err := ctx.SignAndBroadcastTx(tx, from); err != nil {
 			return err
 		}
-		fmt.Print(contract.TxToContract(tx).Address().Hex())
+		fmt.Print(contract.NewContractAddress(from, tx.Common.Nonce).Hex())
 		return nil
 	},
 }
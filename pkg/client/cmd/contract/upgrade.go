@@ -0,0 +1,78 @@
+package contract
+
+import (
+	"github.com/bluele/hypermint/pkg/client"
+	"github.com/bluele/hypermint/pkg/client/contract"
+	"github.com/bluele/hypermint/pkg/client/helper"
+	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/bluele/hypermint/pkg/util"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	flagMigrate = "migrate"
+)
+
+func init() {
+	contractCmd.AddCommand(upgradeCmd)
+	upgradeCmd.Flags().String(helper.FlagAddress, "", "address")
+	upgradeCmd.Flags().String(flagContract, "", "contract address")
+	upgradeCmd.Flags().String(flagCode, "", "new contract code path")
+	upgradeCmd.Flags().Bool(flagMigrate, false, "call migrate function of the new code")
+	upgradeCmd.Flags().StringSlice(flagArgs, nil, "arguments for migrate function")
+	upgradeCmd.Flags().StringSlice(flagArgTypes, nil, "types of arguments")
+	upgradeCmd.Flags().Uint(flagGas, 0, "gas for tx")
+	upgradeCmd.Flags().Uint(flagGasPrice, 0, "gas price for tx")
+	util.CheckRequiredFlag(upgradeCmd, helper.FlagAddress, flagContract, flagCode, flagGas)
+}
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "upgrade contract code",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		viper.BindPFlags(cmd.Flags())
+		ctx, err := client.NewClientContextFromViper()
+		if err != nil {
+			return err
+		}
+
+		code, err := getCode(viper.GetString(flagCode))
+		if err != nil {
+			return err
+		}
+
+		addrs, err := ctx.GetInputAddresses()
+		if err != nil {
+			return err
+		}
+		from := addrs[0]
+
+		nonce, err := ctx.GetNonceByAddress(from)
+		if err != nil {
+			return err
+		}
+		args, err := contract.SerializeCallArgs(
+			viper.GetStringSlice(flagArgs),
+			viper.GetStringSlice(flagArgTypes),
+		)
+		if err != nil {
+			return err
+		}
+		tx := &transaction.ContractUpgradeTx{
+			Address: common.HexToAddress(viper.GetString(flagContract)),
+			Code:    code,
+			Migrate: viper.GetBool(flagMigrate),
+			Args:    args,
+			Common: transaction.CommonTx{
+				Code:     transaction.CONTRACT_UPGRADE,
+				From:     from,
+				Gas:      uint64(viper.GetInt(flagGas)),
+				GasPrice: uint64(viper.GetInt(flagGasPrice)),
+				Nonce:    nonce,
+			},
+		}
+		return ctx.SignAndBroadcastTx(tx, from)
+	},
+}
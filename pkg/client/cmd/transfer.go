@@ -13,9 +13,10 @@ import (
 )
 
 const (
-	flagTo     = "to"
-	flagAmount = "amount"
-	flagGas    = "gas"
+	flagTo       = "to"
+	flagAmount   = "amount"
+	flagGas      = "gas"
+	flagGasPrice = "gas-price"
 )
 
 func init() {
@@ -23,6 +24,7 @@ func init() {
 	transferCmd.Flags().String(flagTo, "", "Addresse sending to")
 	transferCmd.Flags().Uint(flagAmount, 0, "Amount to be spent")
 	transferCmd.Flags().Uint(flagGas, 0, "gas for tx")
+	transferCmd.Flags().Uint(flagGasPrice, 0, "gas price for tx")
 	transferCmd.Flags().String(helper.FlagAddress, "", "Address to sign with")
 	util.CheckRequiredFlag(transferCmd, flagAmount)
 	util.CheckRequiredFlag(transferCmd, flagGas)
@@ -50,16 +52,17 @@ var transferCmd = &cobra.Command{
 			return errors.New("must provide an address to send to")
 		}
 
-		nonce, err := transaction.GetNonceByAddress(from)
+		nonce, err := ctx.GetNonceByAddress(from)
 		if err != nil {
 			return err
 		}
 		tx := &transaction.TransferTx{
 			Common: transaction.CommonTx{
-				Code:  transaction.TRANSFER,
-				From:  from,
-				Gas:   uint64(viper.GetInt(flagGas)),
-				Nonce: nonce,
+				Code:     transaction.TRANSFER,
+				From:     from,
+				Gas:      uint64(viper.GetInt(flagGas)),
+				GasPrice: uint64(viper.GetInt(flagGasPrice)),
+				Nonce:    nonce,
 			},
 			To:     tos[0],
 			Amount: uint64(viper.GetInt(flagAmount)),
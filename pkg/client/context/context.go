@@ -1,6 +1,7 @@
 package context
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -13,7 +14,9 @@ import (
 
 	"github.com/bluele/hypermint/pkg/abci/codec"
 	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
 	"github.com/bluele/hypermint/pkg/client/helper"
+	"github.com/bluele/hypermint/pkg/permission"
 	"github.com/bluele/hypermint/pkg/transaction"
 	"github.com/bluele/hypermint/pkg/util"
 )
@@ -99,12 +102,37 @@ func (ctx *Context) Sign(msg []byte, addr common.Address) ([]byte, error) {
 	return ks.SignHashWithPassphrase(acct, passphrase, msg)
 }
 
-func (ctx *Context) SignAndBroadcastTx(tx transaction.Transaction, addr common.Address) error {
-	sig, err := ctx.Sign(tx.GetSignBytes(), addr)
+// GetChainID returns the chain ID of the node
+func (ctx *Context) GetChainID() (string, error) {
+	node, err := ctx.GetNode()
+	if err != nil {
+		return "", err
+	}
+	status, err := node.Status()
+	if err != nil {
+		return "", err
+	}
+	return status.NodeInfo.Network, nil
+}
+
+// SignTx signs tx for the chain that the node belongs to
+func (ctx *Context) SignTx(tx transaction.Transaction, addr common.Address) error {
+	chainID, err := ctx.GetChainID()
+	if err != nil {
+		return err
+	}
+	sig, err := ctx.Sign(tx.GetSignBytes(chainID), addr)
 	if err != nil {
 		return err
 	}
 	tx.SetSignature(sig)
+	return nil
+}
+
+func (ctx *Context) SignAndBroadcastTx(tx transaction.Transaction, addr common.Address) error {
+	if err := ctx.SignTx(tx, addr); err != nil {
+		return err
+	}
 
 	res, err := ctx.BroadcastTx(tx.Bytes())
 	if err != nil {
@@ -117,11 +145,9 @@ func (ctx *Context) SignAndBroadcastTx(tx transaction.Transaction, addr common.A
 }
 
 func (ctx *Context) SignAndSimulateTx(tx transaction.Transaction, addr common.Address) ([]byte, error) {
-	sig, err := ctx.Sign(tx.GetSignBytes(), addr)
-	if err != nil {
+	if err := ctx.SignTx(tx, addr); err != nil {
 		return nil, err
 	}
-	tx.SetSignature(sig)
 
 	res, err := ctx.Client.ABCIQuery("/app/simulate", tx.Bytes())
 	if err != nil {
@@ -155,3 +181,49 @@ func (ctx *Context) GetBalanceByAddress(addr common.Address) (uint64, error) {
 	}
 	return util.BytesToUint64(res.Response.Value)
 }
+
+// GetNonceByAddress returns the next nonce that addr should use
+func (ctx *Context) GetNonceByAddress(addr common.Address) (uint64, error) {
+	cl, err := ctx.GetNode()
+	if err != nil {
+		return 0, err
+	}
+	res, err := cl.ABCIQuery(fmt.Sprintf("/custom/%v/%v", account.QuerierRoute, account.QueryNonce), addr.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	if res.Response.IsErr() {
+		return 0, errors.New(res.Response.String())
+	}
+	return util.BytesToUint64(res.Response.Value)
+}
+
+// GetRoles returns the roles of addr
+func (ctx *Context) GetRoles(addr common.Address) (*permission.AccountRoles, error) {
+	ar := new(permission.AccountRoles)
+	return ar, ctx.queryPermission(permission.QueryRoles, addr.Bytes(), ar)
+}
+
+// GetPermissions returns all the roles. If the chain is permissionless, it returns nil.
+func (ctx *Context) GetPermissions() (*permission.GenesisState, error) {
+	var gs *permission.GenesisState
+	if err := ctx.queryPermission(permission.QueryState, nil, &gs); err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
+
+func (ctx *Context) queryPermission(path string, data []byte, v interface{}) error {
+	cl, err := ctx.GetNode()
+	if err != nil {
+		return err
+	}
+	res, err := cl.ABCIQuery(fmt.Sprintf("/custom/%v/%v", permission.QuerierRoute, path), data)
+	if err != nil {
+		return err
+	}
+	if res.Response.IsErr() {
+		return errors.New(res.Response.String())
+	}
+	return json.Unmarshal(res.Response.Value, v)
+}
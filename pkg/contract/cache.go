@@ -0,0 +1,69 @@
+package contract
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/perlin-network/life/exec"
+)
+
+// DefaultModuleCacheSize is the default number of compiled modules which ModuleCache keeps
+const DefaultModuleCacheSize = 128
+
+// ModuleCache is a LRU cache of compiled wasm modules keyed by the code hash.
+// A cached module is shared by executions, and each execution has its own memory, globals and resolver.
+type ModuleCache struct {
+	mtx   sync.Mutex
+	size  int
+	ll    *list.List
+	items map[common.Hash]*list.Element
+}
+
+type moduleCacheEntry struct {
+	hash common.Hash
+	// vm is a template which is never executed
+	vm *exec.VirtualMachine
+}
+
+// NewModuleCache returns a cache which keeps the compiled modules up to size
+func NewModuleCache(size int) *ModuleCache {
+	return &ModuleCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[common.Hash]*list.Element),
+	}
+}
+
+// Len returns the number of modules in the cache
+func (c *ModuleCache) Len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.ll.Len()
+}
+
+func (c *ModuleCache) get(h common.Hash) (*exec.VirtualMachine, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	el, ok := c.items[h]
+	if !ok {
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return el.Value.(*moduleCacheEntry).vm, true
+}
+
+func (c *ModuleCache) add(h common.Hash, vm *exec.VirtualMachine) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if el, ok := c.items[h]; ok {
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[h] = c.ll.PushFront(&moduleCacheEntry{hash: h, vm: vm})
+	for c.ll.Len() > c.size {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.items, el.Value.(*moduleCacheEntry).hash)
+	}
+}
@@ -0,0 +1,142 @@
+package contract
+
+import (
+	"encoding/hex"
+	"testing"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// init calls __log with empty message, increments the i32 at the address 0 of memory and returns it
+const testCodeCounter = "0061736d01000000010b0260027f7f017f6000017f020d0103656e76055f5f6c6f67000003020101050301000107080104696e697400010a1d011b004100410010001a4100410028020041016a36020041002802000b"
+
+func TestModuleCache(t *testing.T) {
+	assert := assert.New(t)
+
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	cm := NewContractMapper(key)
+	em := NewEnvManager(key, cm, account.NewAccountMapper(key))
+	cache := NewModuleCache(2)
+	em.SetEngine(NewLifeEngine(cache))
+
+	owner := common.BytesToAddress([]byte("owner"))
+	var put = func(name, code string) common.Address {
+		addr := common.BytesToAddress([]byte(name))
+		b, _ := hex.DecodeString(code)
+		cm.PutCode(ctx, b)
+		cm.Put(ctx, addr, NewContract(owner, addr, b))
+		return addr
+	}
+	addrA := put("A", testCodeCounter)
+	// same code as A
+	addrB := put("B", testCodeCounter)
+
+	// each execution has its own memory and resolver
+	for _, addr := range []common.Address{addrA, addrA, addrB} {
+		env, err := em.Get(ctx, owner, addr, Args{})
+		assert.NoError(err)
+		res, err := env.Exec(ctx, "init")
+		assert.NoError(err)
+		assert.EqualValues(1, res.Code)
+		assert.Equal([]string{""}, env.Logs())
+	}
+	assert.Equal(1, cache.Len())
+
+	// the least recently used module is evicted
+	for _, addr := range []common.Address{put("C", testCode0), put("D", testCodeFail)} {
+		env, err := em.Get(ctx, owner, addr, Args{})
+		assert.NoError(err)
+		env.Exec(ctx, "init")
+	}
+	assert.Equal(2, cache.Len())
+	_, ok := cache.get(CodeHash(mustDecodeHex(testCodeCounter)))
+	assert.False(ok)
+	_, ok = cache.get(CodeHash(mustDecodeHex(testCode0)))
+	assert.True(ok)
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// benchmarkCode returns a module which has n functions. Its init returns a positive value.
+func benchmarkCode(n int) []byte {
+	var leb = func(v int) []byte {
+		var b []byte
+		for {
+			c := byte(v & 0x7f)
+			v >>= 7
+			if v == 0 {
+				return append(b, c)
+			}
+			b = append(b, c|0x80)
+		}
+	}
+	var sec = func(id byte, payload []byte) []byte {
+		return append(append([]byte{id}, leb(len(payload))...), payload...)
+	}
+	// i32.const 1 (i32.const 1 i32.add)*100
+	body := []byte{0x00, 0x41, 0x01}
+	for i := 0; i < 100; i++ {
+		body = append(body, 0x41, 0x01, 0x6a)
+	}
+	body = append(body, 0x0b)
+
+	funcs := leb(n)
+	codes := leb(n)
+	for i := 0; i < n; i++ {
+		funcs = append(funcs, 0x00)
+		codes = append(append(codes, leb(len(body))...), body...)
+	}
+	m := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	m = append(m, sec(1, []byte{0x01, 0x60, 0x00, 0x01, 0x7f})...)
+	m = append(m, sec(3, funcs)...)
+	m = append(m, sec(5, []byte{0x01, 0x00, 0x01})...)
+	m = append(m, sec(7, []byte{0x01, 0x04, 'i', 'n', 'i', 't', 0x00, 0x00})...)
+	m = append(m, sec(10, codes)...)
+	return m
+}
+
+// BenchmarkContractCall executes a contract repeatedly as ContractCallTxs do
+func BenchmarkContractCall(b *testing.B) {
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	require.NoError(b, err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	cm := NewContractMapper(key)
+
+	owner := common.BytesToAddress([]byte("owner"))
+	addr := common.BytesToAddress([]byte("contract"))
+	cm.PutCode(ctx, benchmarkCode(200))
+	cm.Put(ctx, addr, NewContract(owner, addr, benchmarkCode(200)))
+
+	for _, e := range testEngines() {
+		b.Run(e.name, func(b *testing.B) {
+			em := NewEnvManager(key, cm, account.NewAccountMapper(key))
+			em.SetEngine(e.engine)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				env, err := em.Get(ctx, owner, addr, Args{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := env.Exec(ctx, "init"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,36 +1,45 @@
 package contract
 
 import (
-	"github.com/bluele/hypermint/pkg/transaction"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/rlp"
 )
 
 type Contract struct {
 	Owner common.Address
 	Code  []byte
+
+	// addr is the address where the contract instance is stored.
+	addr common.Address
+}
+
+func NewContract(owner, addr common.Address, code []byte) *Contract {
+	return &Contract{Owner: owner, Code: code, addr: addr}
 }
 
 func (c *Contract) Bytes() []byte {
 	return c.Code
 }
 
-func (c *Contract) Decode(b []byte) error {
-	return rlp.DecodeBytes(b, c)
+// Address returns the address of the contract instance.
+// If the contract is not stored yet, it returns an address derived from the code hash.
+func (c *Contract) Address() common.Address {
+	if c.addr != (common.Address{}) {
+		return c.addr
+	}
+	return common.BytesToAddress(crypto.Keccak256(c.Code)[12:])
 }
 
-func (c *Contract) Encode() ([]byte, error) {
-	return rlp.EncodeToBytes(c)
+func (c *Contract) CodeHash() common.Hash {
+	return CodeHash(c.Code)
 }
 
-func (c *Contract) Address() common.Address {
-	return common.BytesToAddress(crypto.Keccak256(c.Code)[12:])
+// CodeHash returns a hash which is a key of the code in the code store
+func CodeHash(code []byte) common.Hash {
+	return crypto.Keccak256Hash(code)
 }
 
-func TxToContract(tx *transaction.ContractDeployTx) *Contract {
-	return &Contract{
-		Owner: tx.Common.From,
-		Code:  tx.Code,
-	}
+// NewContractAddress returns an address of the contract which is instantiated by the deployer
+func NewContractAddress(deployer common.Address, nonce uint64) common.Address {
+	return crypto.CreateAddress(deployer, nonce)
 }
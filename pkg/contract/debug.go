@@ -0,0 +1,138 @@
+package contract
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-interpreter/wagon/wasm"
+	"github.com/go-interpreter/wagon/wasm/leb128"
+)
+
+const (
+	// MaxLogs is the max number of log messages that an execution keeps
+	MaxLogs = 100
+	// DebugMaxLogs is the max number of log messages that DebugInfo keeps
+	DebugMaxLogs = 100
+	// DebugMaxHostCalls is the max number of host calls that DebugInfo keeps
+	DebugMaxHostCalls = 1000
+)
+
+// logBuffer keeps the last MaxLogs messages
+type logBuffer struct {
+	msgs []string
+}
+
+func (b *logBuffer) add(msg string) {
+	if len(b.msgs) >= MaxLogs {
+		b.msgs = b.msgs[1:]
+	}
+	b.msgs = append(b.msgs, msg)
+}
+
+// DebugInfo is a trace of contract execution which is collected in debug mode.
+// It keeps only the last DebugMaxLogs logs and the last DebugMaxHostCalls host calls.
+type DebugInfo struct {
+	StackTrace []string
+	HostCalls  []HostCall
+	Logs       []string
+}
+
+// HostCall is a call to a host function
+type HostCall struct {
+	Contract common.Address
+	Name     string
+	Args     []int64
+}
+
+func (d *DebugInfo) addHostCall(c HostCall) {
+	if len(d.HostCalls) >= DebugMaxHostCalls {
+		d.HostCalls = d.HostCalls[1:]
+	}
+	d.HostCalls = append(d.HostCalls, c)
+}
+
+func (d *DebugInfo) addLog(msg string) {
+	if len(d.Logs) >= DebugMaxLogs {
+		d.Logs = d.Logs[1:]
+	}
+	d.Logs = append(d.Logs, msg)
+}
+
+// setStackTrace captures the call stack of the instance
+func (d *DebugInfo) setStackTrace(addr common.Address, inst Instance) {
+	defer func() {
+		// debug mode never stops the node
+		if r := recover(); r != nil {
+			d.StackTrace = append(d.StackTrace, fmt.Sprintf("failed to capture stack trace: %v", r))
+		}
+	}()
+	d.StackTrace = nil
+	for _, s := range inst.StackTrace() {
+		d.StackTrace = append(d.StackTrace, fmt.Sprintf("%v %s", addr.Hex(), s))
+	}
+}
+
+// functionNames returns the function names in the name section of the module.
+// The raw bytes of custom sections which wagon provides start with the section name,
+// so life cannot read the function names by itself.
+func functionNames(m *wasm.Module) map[int]string {
+	names := make(map[int]string)
+	for _, sec := range m.Customs {
+		if sec.Name != "name" {
+			continue
+		}
+		r := bytes.NewReader(sec.RawSection.Bytes)
+		if _, err := readName(r); err != nil {
+			return names
+		}
+		for {
+			ty, err := leb128.ReadVarUint32(r)
+			if err != nil {
+				return names
+			}
+			payload, err := readName(r)
+			if err != nil {
+				return names
+			}
+			// 1 is the id of function names subsection
+			if ty != 1 {
+				continue
+			}
+			pr := bytes.NewReader([]byte(payload))
+			count, err := leb128.ReadVarUint32(pr)
+			if err != nil {
+				return names
+			}
+			for i := uint32(0); i < count; i++ {
+				idx, err := leb128.ReadVarUint32(pr)
+				if err != nil {
+					return names
+				}
+				name, err := readName(pr)
+				if err != nil {
+					return names
+				}
+				names[int(idx)] = name
+			}
+		}
+	}
+	return names
+}
+
+// readName reads a length-prefixed bytes as string
+func readName(r *bytes.Reader) (string, error) {
+	n, err := leb128.ReadVarUint32(r)
+	if err != nil {
+		return "", err
+	}
+	if int64(n) > int64(r.Len()) {
+		return "", io.ErrUnexpectedEOF
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,112 @@
+package contract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/db"
+	"github.com/bluele/hypermint/pkg/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// init calls __log with empty message, and traps. It has a name section.
+const testCodeTrap = "0061736d01000000010b0260027f7f017f6000017f020d0103656e76055f5f6c6f67000003020101050301000107080104696e697400010a0c010a004100410010001a000b0013046e616d65010c010109747261705f696e6974"
+
+func TestDebugInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+
+	code, _ := hex.DecodeString(testCodeTrap)
+	c := &Contract{Owner: common.BytesToAddress([]byte("owner")), Code: code}
+	var exec = func(debug bool, engine Engine) *Env {
+		env := &Env{
+			Contract:   c,
+			DB:         db.NewVersionedDB(ctx.KVStore(key)),
+			Debug:      debug,
+			VMProvider: EngineVMProvider(engine),
+		}
+		_, err := env.Exec(ctx, "init")
+		assert.Error(err)
+		return env
+	}
+
+	env := exec(false, NewLifeEngine(nil))
+	assert.Nil(env.DebugInfo())
+	// logs are kept even if the execution fails
+	assert.Equal([]string{""}, env.Logs())
+
+	for _, e := range testEngines() {
+		d := exec(true, e.engine).DebugInfo()
+		if assert.NotNil(d, e.name) {
+			assert.Len(d.StackTrace, 1, e.name)
+			assert.True(strings.HasSuffix(d.StackTrace[0], "trap_init"), d.StackTrace[0])
+			assert.Equal([]HostCall{{Contract: c.Address(), Name: "__log", Args: []int64{0, 0}}}, d.HostCalls)
+			assert.Equal([]string{""}, d.Logs)
+		}
+	}
+}
+
+func TestEnvManagerDebug(t *testing.T) {
+	assert := assert.New(t)
+
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	cm := NewContractMapper(key)
+	em := NewEnvManager(key, cm, nil)
+	em.SetDebug(true)
+
+	code, _ := hex.DecodeString(testCodeTrap)
+	owner := common.BytesToAddress([]byte("owner"))
+	addr := common.BytesToAddress([]byte("trap"))
+	cm.PutCode(ctx, code)
+	cm.Put(ctx, addr, NewContract(owner, addr, code))
+
+	// the trace is collected only in the simulation
+	env, err := em.Get(ctx, owner, addr, Args{})
+	assert.NoError(err)
+	assert.False(env.Debug)
+	env, err = em.Get(ctx.WithIsSimulate(true), owner, addr, Args{})
+	assert.NoError(err)
+	assert.True(env.Debug)
+}
+
+func TestDebugInfoLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	d := new(DebugInfo)
+	for i := 0; i < DebugMaxLogs+1; i++ {
+		d.addLog(fmt.Sprint(i))
+	}
+	assert.Len(d.Logs, DebugMaxLogs)
+	assert.Equal("1", d.Logs[0])
+	assert.Equal(fmt.Sprint(DebugMaxLogs), d.Logs[DebugMaxLogs-1])
+
+	for i := 0; i < DebugMaxHostCalls+1; i++ {
+		d.addHostCall(HostCall{Name: fmt.Sprint(i)})
+	}
+	assert.Len(d.HostCalls, DebugMaxHostCalls)
+	assert.Equal("1", d.HostCalls[0].Name)
+}
+
+func TestLogsLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	b := new(logBuffer)
+	for i := 0; i < MaxLogs+1; i++ {
+		b.add(fmt.Sprint(i))
+	}
+	assert.Len(b.msgs, MaxLogs)
+	assert.Equal("1", b.msgs[0])
+	assert.Equal(fmt.Sprint(MaxLogs), b.msgs[MaxLogs-1])
+}
@@ -0,0 +1,104 @@
+package contract
+
+import (
+	"fmt"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+)
+
+const (
+	// EngineLife is the interpreter of life, which is the default engine
+	EngineLife = "life"
+	// EngineInterp is the interpreter of the interp package
+	EngineInterp = "interp"
+)
+
+var engineKey = []byte("vm_engine")
+
+// Engine is a wasm runtime which executes contracts.
+// The results and the gas of contracts are a part of the consensus, so every node of a chain must run
+// the engine which the genesis configures.
+type Engine interface {
+	// Name returns the name of the engine. The configurations of an engine have the same name.
+	Name() string
+	// Instantiate creates an instance of the code. The imports of the code are resolved with hfs.
+	// The instance must not exceed the memory and call stack limits of params.
+	Instantiate(code []byte, hfs HostFunctions, params VMParams) (Instance, error)
+}
+
+// Instance is an instantiated wasm module
+type Instance interface {
+	// Call calls the exported function, and charges the gas consumed by the instance to the meter.
+	// If the meter runs out of gas, this panics with sdk.ErrorOutOfGas.
+	Call(meter sdk.GasMeter, entry string, params ...int64) (int64, error)
+	// Memory returns the linear memory of the instance
+	Memory() []byte
+	// StackTrace returns the current call stack of the instance
+	StackTrace() []string
+}
+
+// HostFunction is a function which contracts import.
+// mem is the linear memory of the caller, and args are the arguments of the call.
+type HostFunction func(mem []byte, args []int64) int64
+
+// HostFunctions resolves the functions which contracts import
+type HostFunctions interface {
+	Resolve(module, field string) (HostFunction, error)
+}
+
+// EngineVMProvider returns a VMProvider which instantiates the contracts on the engine
+func EngineVMProvider(engine Engine) VMProvider {
+	return func(env *Env) (Instance, error) {
+		return engine.Instantiate(env.Contract.Code, NewResolver(env), env.params())
+	}
+}
+
+// NewEngine returns the engine of given name. An empty name means EngineLife.
+func NewEngine(name string) (Engine, error) {
+	switch name {
+	case "", EngineLife:
+		return NewLifeEngine(NewModuleCache(DefaultModuleCacheSize)), nil
+	case EngineInterp:
+		return NewInterpEngine(), nil
+	default:
+		return nil, fmt.Errorf("unknown engine: %v", name)
+	}
+}
+
+// Engines returns the names of available engines
+func Engines() []string {
+	return []string{EngineLife, EngineInterp}
+}
+
+// SetChainEngine stores the name of the engine which the chain executes contracts on.
+// It fails if the manager runs another engine. An empty name means EngineLife, and it is not stored.
+func (em *EnvManager) SetChainEngine(ctx sdk.Context, name string) error {
+	if err := em.checkEngine(name); err != nil {
+		return err
+	}
+	if name != "" {
+		ctx.KVStore(em.key).Set(engineKey, []byte(name))
+	}
+	return nil
+}
+
+// GetChainEngine returns the name of the engine which the chain executes contracts on.
+// An empty name means EngineLife.
+func (em *EnvManager) GetChainEngine(ctx sdk.Context) string {
+	return string(ctx.MultiStore().GetKVStore(em.key).Get(engineKey))
+}
+
+// CheckChainEngine returns an error if the manager runs another engine than the chain
+func (em *EnvManager) CheckChainEngine(ctx sdk.Context) error {
+	return em.checkEngine(em.GetChainEngine(ctx))
+}
+
+func (em *EnvManager) checkEngine(name string) error {
+	if name == "" {
+		name = EngineLife
+	}
+	if name != em.engine.Name() {
+		return fmt.Errorf("the chain executes contracts on the engine %v, but the node runs %v", name, em.engine.Name())
+	}
+	return nil
+}
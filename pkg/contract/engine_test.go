@@ -0,0 +1,107 @@
+package contract
+
+import (
+	"encoding/hex"
+	"testing"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// init writes "value" to "key", and then writes "new" to "key"
+const testCodeWriteState = "0061736d01000000010d0260047f7f7f7f017f6000017f02150103656e760d5f5f77726974655f7374617465000003020101050301000107080104696e697400010a1c011a00410041034103410510001a410041034108410310001a41000b0b11010041000b0b6b657976616c75656e6577"
+
+// testEngines are the engines and the configurations of them, which must produce the same results
+func testEngines() []struct {
+	name   string
+	engine Engine
+} {
+	return []struct {
+		name   string
+		engine Engine
+	}{
+		{"uncached", NewLifeEngine(nil)},
+		{"cached", NewLifeEngine(NewModuleCache(DefaultModuleCacheSize))},
+		{"interp", NewInterpEngine()},
+	}
+}
+
+// engineResult is a result of an execution which every configuration must agree on
+type engineResult struct {
+	Code       int32
+	Response   []byte
+	Failed     bool
+	RWSetsHash []byte
+	GasUsed    uint64
+}
+
+func execOnEngine(t *testing.T, engine Engine, codes []string) []engineResult {
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	require.NoError(t, err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	cm := NewContractMapper(key)
+	em := NewEnvManager(key, cm, account.NewAccountMapper(key))
+	em.SetEngine(engine)
+
+	owner := common.BytesToAddress([]byte("owner"))
+	var results []engineResult
+	// executes every contract twice to run on the compiled module too
+	for i := 0; i < 2; i++ {
+		for j, code := range codes {
+			addr := common.BytesToAddress([]byte{byte(j + 1)})
+			b, _ := hex.DecodeString(code)
+			cm.PutCode(ctx, b)
+			cm.Put(ctx, addr, NewContract(owner, addr, b))
+
+			env, err := em.Get(ctx, owner, addr, Args{})
+			require.NoError(t, err)
+			ctx := ctx.WithGasMeter(sdk.NewGasMeter(1000000))
+			res, err := env.Exec(ctx, "init")
+			r := engineResult{Failed: err != nil, GasUsed: ctx.GasMeter().GasConsumed()}
+			if res != nil {
+				r.Code = res.Code
+				r.Response = res.Response
+				r.RWSetsHash = res.State.RWSets().Hash()
+			}
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+// withoutGas returns the results whose GasUsed are cleared
+func withoutGas(results []engineResult) []engineResult {
+	var rs []engineResult
+	for _, r := range results {
+		r.GasUsed = 0
+		rs = append(rs, r)
+	}
+	return rs
+}
+
+// TestModuleCacheConformance checks that the compiled modules in the cache and the other engines behave like the freshly compiled ones.
+// The gas is compared only between the configurations of the same engine.
+func TestModuleCacheConformance(t *testing.T) {
+	codes := []string{testCode0, testCode1, testCodeFail, testCodeRevert, testCodeTrap, testCodeCounter, testCodeWriteState}
+	expected := execOnEngine(t, NewLifeEngine(nil), codes)
+	for _, e := range testEngines() {
+		t.Run(e.name, func(t *testing.T) {
+			results := execOnEngine(t, e.engine, codes)
+			if e.engine.Name() == EngineLife {
+				assert.Equal(t, expected, results)
+				return
+			}
+			assert.Equal(t, withoutGas(expected), withoutGas(results))
+			for i := range codes {
+				assert.NotZero(t, results[i].GasUsed)
+				assert.Equal(t, results[i].GasUsed, results[len(codes)+i].GasUsed)
+			}
+		})
+	}
+}
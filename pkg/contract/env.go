@@ -3,13 +3,14 @@ package contract
 import (
 	"fmt"
 
+	"github.com/bluele/hypermint/pkg/account"
 	"github.com/bluele/hypermint/pkg/contract/event"
 	"github.com/bluele/hypermint/pkg/db"
 	"github.com/bluele/hypermint/pkg/logger"
+	"github.com/bluele/hypermint/pkg/permission"
 
 	sdk "github.com/bluele/hypermint/pkg/abci/types"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/perlin-network/life/exec"
 )
 
 type Env struct {
@@ -17,15 +18,30 @@ type Env struct {
 	Logger   logger.Logger
 	Sender   common.Address
 	Args     Args
+	Value    uint64 // native coin which is sent by the caller
+	Params   VMParams
 	response []byte
 
-	EnvManager *EnvManager
-	Contract   *Contract
-	VMProvider VMProvider
+	Depth    int  // depth of the call. The top-level call is 0
+	ReadOnly bool // if true, the contract cannot modify any state
+	caller   *Env
+	locked   bool
+	callErr  *CallError // error of the last call to other contract
 
-	DB      *db.VersionedDB
-	entries []*event.Entry
-	state   State
+	Debug bool // if true, the trace of execution is collected into DebugInfo
+	debug *DebugInfo
+	logs  *logBuffer
+	usage *usage
+
+	EnvManager    *EnvManager
+	Contract      *Contract
+	VMProvider    VMProvider
+	AccountMapper account.AccountMapper
+
+	DB       *db.VersionedDB
+	entries  []*event.Entry
+	state    State
+	gasMeter sdk.GasMeter
 }
 
 type Args struct {
@@ -89,22 +105,11 @@ func (s *State) AddEvents(evs ...*event.Event) {
 	s.evs = append(s.evs, evs...)
 }
 
-type VMProvider func(*Env) (*VM, error)
+type VMProvider func(*Env) (Instance, error)
 
-func DefaultVMProvider(env *Env) (*VM, error) {
-	v, err := exec.NewVirtualMachine(env.Contract.Code, exec.VMConfig{
-		EnableJIT:          false,
-		DefaultMemoryPages: 128,
-		DefaultTableSize:   65536,
-	}, NewResolver(env), nil)
-	if err != nil {
-		return nil, err
-	}
-	return &VM{VirtualMachine: v}, nil
-}
-
-type VM struct {
-	*exec.VirtualMachine
+// DefaultVMProvider compiles the code of the contract on every execution
+func DefaultVMProvider(env *Env) (Instance, error) {
+	return NewLifeEngine(nil).Instantiate(env.Contract.Code, NewResolver(env), env.params())
 }
 
 type Result struct {
@@ -113,30 +118,68 @@ type Result struct {
 	State    State
 }
 
-func (env *Env) Exec(ctx sdk.Context, entry string) (*Result, error) {
+// usage is the resources which the executions in a transaction have used
+type usage struct {
+	events int
+}
+
+// Exec executes the entry function of the contract.
+// If the contract reverts or exceeds a limit of VMParams, Exec returns *RevertError or *LimitError only at the top-level call.
+func (env *Env) Exec(ctx sdk.Context, entry string) (res *Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if env.caller != nil {
+				panic(r)
+			}
+			switch e := r.(type) {
+			case *RevertError:
+				res, err = nil, e
+			case *LimitError:
+				res, err = nil, e
+			default:
+				panic(r)
+			}
+		}
+	}()
+	params := env.params()
+	size := 0
+	for _, v := range env.Args.values {
+		size += len(v)
+	}
+	checkLimit("args size", size, params.MaxArgsSize)
 	vmProvider := env.VMProvider
 	if vmProvider == nil {
 		vmProvider = DefaultVMProvider
 	}
+	if env.Debug && env.debug == nil {
+		env.debug = new(DebugInfo)
+	}
+	if env.logs == nil {
+		env.logs = new(logBuffer)
+	}
+	if env.usage == nil {
+		env.usage = new(usage)
+	}
 	vm, err := vmProvider(env)
 	if err != nil {
 		return nil, err
 	}
-	id, ok := vm.GetFunctionExport(entry)
-	if !ok {
-		return nil, fmt.Errorf("entry point not found")
-	}
-	ret, err := vm.Run(id)
-	if err != nil {
-		// TODO add debug option?
-		// vm.PrintStackTrace()
+	env.Context = ctx
+	env.gasMeter = ctx.GasMeter()
+	ret, err := vm.Call(env.gasMeter, entry)
+	if err == ErrEntryNotFound {
+		return nil, err
+	} else if err != nil {
+		if env.debug != nil {
+			env.debug.setStackTrace(env.Contract.Address(), vm)
+		}
 		return nil, err
 	}
 	code := int32(ret)
-	res := env.GetReponse()
+	response := env.GetReponse()
 	if code < 0 {
 		// TODO add error msg from call stacks
-		return &Result{Code: code, Response: res}, fmt.Errorf("failed to execute contract exitcode=%v description=%v", code, string(res))
+		return &Result{Code: code, Response: response}, fmt.Errorf("failed to execute contract exitcode=%v description=%v", code, string(response))
 	}
 
 	// Update state
@@ -148,11 +191,69 @@ func (env *Env) Exec(ctx sdk.Context, entry string) (*Result, error) {
 
 	return &Result{
 		Code:     code,
-		Response: res,
+		Response: response,
 		State:    env.state,
 	}, nil
 }
 
+// setCaller makes env a callee of the caller.
+// It fails if the call exceeds the max call depth or re-enters the locked contract.
+func (env *Env) setCaller(caller *Env, readOnly bool) error {
+	env.caller = caller
+	env.Depth = caller.Depth + 1
+	if env.Depth > int(env.params().MaxCallDepth) {
+		return ErrMaxCallDepthExceeded
+	}
+	addr := env.Contract.Address()
+	for e := caller; e != nil; e = e.caller {
+		if e.locked && e.Contract.Address() == addr {
+			return ErrReentrantCall
+		}
+	}
+	env.ReadOnly = caller.ReadOnly || readOnly
+	env.DB.SetReadOnly(env.ReadOnly)
+	env.Debug = caller.Debug
+	env.debug = caller.debug
+	env.logs = caller.logs
+	env.usage = caller.usage
+	return nil
+}
+
+// txSender returns the sender of the top-level call, which is the sender of the tx
+func (env *Env) txSender() common.Address {
+	for env.caller != nil {
+		env = env.caller
+	}
+	return env.Sender
+}
+
+// params returns the params of the env, or DefaultVMParams if they are not set
+func (env *Env) params() VMParams {
+	if env.Params == (VMParams{}) {
+		return DefaultVMParams()
+	}
+	return env.Params
+}
+
+// Logs returns messages which contracts have output with __log in the execution.
+// It includes the messages of the contracts called by this contract.
+func (env *Env) Logs() []string {
+	if env.logs == nil {
+		return nil
+	}
+	return env.logs.msgs
+}
+
+// DebugInfo returns the trace of execution. It returns nil unless debug mode is enabled.
+func (env *Env) DebugInfo() *DebugInfo {
+	return env.debug
+}
+
+// LockReentrancy makes any reentrant call to the contract fail until this call returns
+func (env *Env) LockReentrancy() {
+	env.locked = true
+}
+
 func (env *Env) SetResponse(v []byte) {
 	env.response = v
 }
@@ -162,28 +263,127 @@ func (env *Env) GetReponse() []byte {
 }
 
 type EnvManager struct {
-	key sdk.StoreKey
-	cm  ContractMapper
+	key         sdk.StoreKey
+	cm          ContractMapper
+	am          account.AccountMapper
+	pm          permission.PermissionMapper
+	debug       bool
+	deliverLogs bool
+	engine      Engine
 }
 
-func NewEnvManager(key sdk.StoreKey, cm ContractMapper) *EnvManager {
+func NewEnvManager(key sdk.StoreKey, cm ContractMapper, am account.AccountMapper) *EnvManager {
 	return &EnvManager{
-		key: key,
-		cm:  cm,
+		key:    key,
+		cm:     cm,
+		am:     am,
+		engine: NewLifeEngine(NewModuleCache(DefaultModuleCacheSize)),
 	}
 }
 
+// SetEngine sets the engine which executes contracts
+func (em *EnvManager) SetEngine(engine Engine) {
+	em.engine = engine
+}
+
+// SetPermissionMapper makes the calls and the creations of contracts by contracts be restricted by the roles.
+// The roles of the sender of the tx are checked at every depth, so a contract can call another contract
+// only if the sender can call it directly, including by the caller list of the callee, and can create
+// contracts only if the sender can deploy. The roles of the calling contract are not used.
+func (em *EnvManager) SetPermissionMapper(pm permission.PermissionMapper) {
+	em.pm = pm
+}
+
+// checkCall returns ErrPermissionDenied if the sender of the tx cannot call the contract
+func (em *EnvManager) checkCall(ctx sdk.Context, sender, addr common.Address) error {
+	if em.pm != nil && !em.pm.CanCall(ctx, sender, addr) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// checkDeploy returns ErrPermissionDenied if the sender of the tx cannot deploy contracts
+func (em *EnvManager) checkDeploy(ctx sdk.Context, sender common.Address) error {
+	if em.pm != nil && !em.pm.CanDeploy(ctx, sender) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// SetDeliverLogs makes the logs of contracts be included in the result of delivered txs.
+// The logs are always included in the result of simulation.
+func (em *EnvManager) SetDeliverLogs(deliverLogs bool) {
+	em.deliverLogs = deliverLogs
+}
+
+func (em *EnvManager) DeliverLogs() bool {
+	return em.deliverLogs
+}
+
+// SetDebug enables debug mode of the envs which the manager creates for simulation.
+// The trace is never collected while txs are checked or delivered.
+func (em *EnvManager) SetDebug(debug bool) {
+	em.debug = debug
+}
+
 func (em *EnvManager) Get(ctx sdk.Context, sender, addr common.Address, args Args) (*Env, error) {
 	c, err := em.cm.Get(ctx, addr)
 	if err != nil {
 		return nil, err
 	}
 	return &Env{
-		Context:    ctx,
-		Sender:     sender,
-		EnvManager: em,
-		Contract:   c,
-		DB:         db.NewVersionedDB(ctx.KVStore(em.key).Prefix(addr.Bytes())),
-		Args:       args,
+		Context:       ctx,
+		Sender:        sender,
+		EnvManager:    em,
+		Contract:      c,
+		AccountMapper: em.am,
+		DB:            db.NewVersionedDB(ctx.KVStore(em.key).Prefix(addr.Bytes())),
+		Args:          args,
+		Debug:         em.debug && ctx.IsSimulate(),
+		Params:        em.GetParams(ctx),
+		VMProvider:    EngineVMProvider(em.engine),
 	}, nil
 }
+
+// CreateContract creates a new contract instance of the wasm module whose owner is the creator.
+// The address of the instance is derived from the creator and the nonce of it.
+func (em *EnvManager) CreateContract(ctx sdk.Context, creator common.Address, code []byte) (common.Address, error) {
+	if err := ValidateCode(code); err != nil {
+		return common.Address{}, err
+	}
+	addr, err := em.createContract(ctx, creator, code)
+	if err != nil {
+		return addr, err
+	}
+	em.cm.PutCode(ctx, code)
+	return addr, nil
+}
+
+// CreateContractFromHash creates a new contract instance of the code which has been uploaded like CreateContract
+func (em *EnvManager) CreateContractFromHash(ctx sdk.Context, creator common.Address, hash common.Hash) (common.Address, error) {
+	// the uploaded code has been validated
+	code, err := em.cm.GetCode(ctx, hash)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return em.createContract(ctx, creator, code)
+}
+
+func (em *EnvManager) createContract(ctx sdk.Context, creator common.Address, code []byte) (common.Address, error) {
+	if em.am == nil {
+		return common.Address{}, ErrAccountMapperNotFound
+	}
+	nonce, err := em.am.GetNonce(ctx, creator)
+	if err != nil {
+		return common.Address{}, err
+	}
+	addr := NewContractAddress(creator, nonce)
+	if em.cm.Has(ctx, addr) {
+		return addr, fmt.Errorf("already exists: %v", addr.Hex())
+	}
+	if _, err := em.am.IncrNonce(ctx, creator); err != nil {
+		return addr, err
+	}
+	em.cm.Put(ctx, addr, NewContract(creator, addr, code))
+	return addr, nil
+}
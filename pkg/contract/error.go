@@ -1,7 +1,64 @@
 package contract
 
-import "errors"
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+)
 
 var (
 	ErrContractNotFound = errors.New("contract not found")
+	ErrCodeNotFound     = errors.New("code not found")
+	ErrEntryNotFound    = errors.New("entry point not found")
+
+	ErrMaxCallDepthExceeded = errors.New("max call depth exceeded")
+	ErrReentrantCall        = errors.New("reentrant call to the locked contract")
+	ErrReadOnly             = errors.New("state modification in read-only call")
+	ErrPermissionDenied     = errors.New("permission denied")
 )
+
+// RevertCodespace is the codespace of the tx result which a contract has reverted
+const RevertCodespace types.CodespaceType = "contract"
+
+// CodeCallFailed is the code of CallError which is not caused by the exit code of the callee
+const CodeCallFailed int32 = -1
+
+// CallError is an error of the callee, which the caller contract can read.
+type CallError struct {
+	Code    int32
+	Message string
+}
+
+// NewCallError returns a CallError from the result and the error of the callee
+func NewCallError(res *Result, err error) *CallError {
+	if res != nil && res.Code < 0 {
+		return &CallError{Code: res.Code, Message: string(res.Response)}
+	}
+	return &CallError{Code: CodeCallFailed, Message: err.Error()}
+}
+
+func (e CallError) Error() string {
+	return fmt.Sprintf("failed to call contract: code=%v message=%v", e.Code, e.Message)
+}
+
+// Bytes returns bytes of the error
+// bytes format is <code: 4byte>|<message>
+func (e CallError) Bytes() []byte {
+	b := make([]byte, 4+len(e.Message))
+	binary.BigEndian.PutUint32(b, uint32(e.Code))
+	copy(b[4:], e.Message)
+	return b
+}
+
+// RevertError aborts the whole transaction with the code which the contract defines.
+type RevertError struct {
+	Address string
+	Code    uint32
+	Message string
+}
+
+func (e RevertError) Error() string {
+	return fmt.Sprintf("contract reverted: address=%v code=%v message=%v", e.Address, e.Code, e.Message)
+}
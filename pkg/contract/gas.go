@@ -0,0 +1,58 @@
+package contract
+
+import (
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/perlin-network/life/compiler"
+	"github.com/perlin-network/life/utils"
+)
+
+const (
+	// GasPerInstruction is the gas cost of executing a wasm instruction
+	GasPerInstruction = 1
+	// GasPerHostCall is the gas cost of calling a host function
+	GasPerHostCall = 100
+)
+
+const (
+	gasDescInstruction = "WasmInstruction"
+)
+
+// DefaultGasPolicy returns a gas policy that charges GasPerInstruction for each instruction.
+// NOTE: life doesn't count jump instructions, so a basic block which consists of only a jump is free.
+func DefaultGasPolicy() compiler.GasPolicy {
+	return &compiler.SimpleGasPolicy{GasPerInstruction: GasPerInstruction}
+}
+
+// RunWithGasMeter runs a function of given id, and charges the gas consumed by vm to the meter.
+// If the meter runs out of gas, this panics with sdk.ErrorOutOfGas.
+// The vm must be created with ReturnOnGasLimitExceeded option.
+func (vm *VM) RunWithGasMeter(meter sdk.GasMeter, entryID int, params ...int64) (int64, error) {
+	vm.Ignite(entryID, params...)
+	for !vm.Exited {
+		remaining := meter.Limit() - meter.GasConsumed()
+		if remaining == 0 {
+			meter.ConsumeGas(1, gasDescInstruction)
+		}
+		used := vm.Gas
+		if limit := used + remaining; limit < used {
+			// no limit
+			vm.Config.GasLimit = 0
+		} else {
+			vm.Config.GasLimit = limit
+		}
+		vm.Execute()
+		meter.ConsumeGas(vm.Gas-used, gasDescInstruction)
+		if vm.GasLimitExceeded {
+			meter.ConsumeGas(remaining-(vm.Gas-used)+1, gasDescInstruction)
+		}
+		if vm.Delegate != nil {
+			vm.Delegate()
+			vm.Delegate = nil
+		}
+	}
+
+	if vm.ExitError != nil {
+		return -1, utils.UnifyError(vm.ExitError)
+	}
+	return vm.ReturnValue, nil
+}
@@ -0,0 +1,57 @@
+package contract
+
+import (
+	"encoding/hex"
+	"testing"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGasCode is a wasm module which exports following functions:
+// loop: runs an infinite loop
+// resp: calls __set_response once and returns
+const testGasCode = "0061736d01000000010b026000017f60027f7f017f02160103656e760e5f5f7365745f726573706f6e736500010303020000070f02046c6f6f700001047265737000020a16020b00034041010d000b41000b08004100410010000b"
+
+func newTestGasVM(t *testing.T) (*Env, *VM) {
+	code, err := hex.DecodeString(testGasCode)
+	require.NoError(t, err)
+	env := &Env{Contract: &Contract{Code: code}}
+	inst, err := DefaultVMProvider(env)
+	require.NoError(t, err)
+	return env, inst.(*VM)
+}
+
+func TestRunWithGasMeter(t *testing.T) {
+	t.Run("out of gas", func(t *testing.T) {
+		env, vm := newTestGasVM(t)
+		env.gasMeter = sdk.NewGasMeter(100000)
+		id, ok := vm.GetFunctionExport("loop")
+		require.True(t, ok)
+		assert.PanicsWithValue(t, sdk.ErrorOutOfGas{Descriptor: gasDescInstruction}, func() {
+			vm.RunWithGasMeter(env.gasMeter, id)
+		})
+	})
+
+	t.Run("host call", func(t *testing.T) {
+		env, vm := newTestGasVM(t)
+		env.gasMeter = sdk.NewGasMeter(GasPerHostCall + 100)
+		id, ok := vm.GetFunctionExport("resp")
+		require.True(t, ok)
+		ret, err := vm.RunWithGasMeter(env.gasMeter, id)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, ret)
+		assert.True(t, env.gasMeter.GasConsumed() > GasPerHostCall)
+	})
+
+	t.Run("host call out of gas", func(t *testing.T) {
+		env, vm := newTestGasVM(t)
+		env.gasMeter = sdk.NewGasMeter(GasPerHostCall)
+		id, ok := vm.GetFunctionExport("resp")
+		require.True(t, ok)
+		assert.PanicsWithValue(t, sdk.ErrorOutOfGas{Descriptor: "__set_response"}, func() {
+			vm.RunWithGasMeter(env.gasMeter, id)
+		})
+	})
+}
@@ -0,0 +1,100 @@
+package contract
+
+import (
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/db"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// GenesisState is the state of the codes and contracts in the genesis
+type GenesisState struct {
+	Codes     []hexutil.Bytes   `json:"codes,omitempty"`
+	Contracts []GenesisContract `json:"contracts,omitempty"`
+}
+
+// GenesisContract is a contract instance and its state
+type GenesisContract struct {
+	Address  common.Address `json:"address"`
+	Owner    common.Address `json:"owner"`
+	CodeHash common.Hash    `json:"code_hash"`
+	State    []GenesisValue `json:"state,omitempty"`
+}
+
+// GenesisValue is a value in the state of a contract and the version which wrote it
+type GenesisValue struct {
+	Key     hexutil.Bytes `json:"key"`
+	Value   hexutil.Bytes `json:"value"`
+	Version db.Version    `json:"version"`
+}
+
+// ExportGenesis returns all codes and contracts with their versioned states
+func (em *EnvManager) ExportGenesis(ctx sdk.Context) (*GenesisState, error) {
+	gs := new(GenesisState)
+	em.cm.IterateCodes(ctx, func(_ common.Hash, code []byte) bool {
+		gs.Codes = append(gs.Codes, code)
+		return false
+	})
+	var err error
+	em.cm.IterateContracts(ctx, func(addr, owner common.Address, codeHash common.Hash) bool {
+		var state []GenesisValue
+		state, err = em.exportState(ctx, addr)
+		if err != nil {
+			return true
+		}
+		gs.Contracts = append(gs.Contracts, GenesisContract{
+			Address:  addr,
+			Owner:    owner,
+			CodeHash: codeHash,
+			State:    state,
+		})
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gs, nil
+}
+
+func (em *EnvManager) exportState(ctx sdk.Context, addr common.Address) ([]GenesisValue, error) {
+	var state []GenesisValue
+	it := ctx.KVStore(em.key).Prefix(addr.Bytes()).Iterator(nil, nil)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		vo, err := db.BytesToValueObject(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		state = append(state, GenesisValue{Key: it.Key(), Value: vo.Value, Version: vo.Version})
+	}
+	return state, nil
+}
+
+// ImportGenesis stores the codes and contracts of the genesis.
+// The states of contracts keep the versions which they are exported with.
+func (em *EnvManager) ImportGenesis(ctx sdk.Context, gs GenesisState) error {
+	for _, code := range gs.Codes {
+		em.cm.PutCode(ctx, code)
+	}
+	for _, gc := range gs.Contracts {
+		code, err := em.cm.GetCode(ctx, gc.CodeHash)
+		if err != nil {
+			return err
+		}
+		em.cm.Put(ctx, gc.Address, NewContract(gc.Owner, gc.Address, code))
+		kvs := ctx.KVStore(em.key).Prefix(gc.Address.Bytes())
+		for _, v := range gc.State {
+			kvs.Set(v.Key, db.ValueObject{Value: v.Value, Version: v.Version}.Marshal())
+		}
+	}
+	return nil
+}
+
+// InitState writes the initial state of the contract. The values have the version of the context.
+func (em *EnvManager) InitState(ctx sdk.Context, addr common.Address, state []db.KV) {
+	version := db.Version{Height: uint32(ctx.BlockHeight()), TxIdx: ctx.TxIndex()}
+	kvs := ctx.KVStore(em.key).Prefix(addr.Bytes())
+	for _, kv := range state {
+		kvs.Set(kv.Key, db.ValueObject{Value: kv.Value, Version: version}.Marshal())
+	}
+}
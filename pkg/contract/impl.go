@@ -1,6 +1,8 @@
 package contract
 
 import (
+	"encoding/binary"
+
 	"github.com/bluele/hypermint/pkg/util"
 	"github.com/bluele/hypermint/pkg/contract/event"
 	"github.com/ethereum/go-ethereum/common"
@@ -70,7 +72,7 @@ func GetArg(ps Process, idx, offset int, buf Writer) int {
 }
 
 func Log(ps Process, msg Reader) int {
-	ps.Logger().Debug(string(msg.Read()))
+	ps.Log(string(msg.Read()))
 	return 0
 }
 
@@ -84,6 +86,57 @@ func GetContractAddress(ps Process, w Writer) int {
 	return w.Write(c[:])
 }
 
+func GetBlockHeight(ps Process) int64 {
+	return ps.BlockHeight()
+}
+
+// GetBlockTime returns the block time as unix seconds
+func GetBlockTime(ps Process) int64 {
+	return ps.BlockTime().Unix()
+}
+
+func GetChainID(ps Process, w Writer) int {
+	return w.Write([]byte(ps.ChainID()))
+}
+
+func GetProposer(ps Process, w Writer) int {
+	p := ps.Proposer()
+	return w.Write(p[:])
+}
+
+func GetTxHash(ps Process, w Writer) int {
+	return w.Write(ps.TxHash())
+}
+
+// GetBalance writes the balance of the address as 8 bytes big endian
+func GetBalance(ps Process, addr Reader, w Writer) int {
+	b, err := ps.GetBalance(common.BytesToAddress(addr.Read()))
+	if err != nil {
+		ps.Logger().Debug("failed to execute GetBalance", "err", err)
+		return -1
+	}
+	return w.Write(uint64ToBytes(b))
+}
+
+// GetValue writes the amount sent to the contract as 8 bytes big endian
+func GetValue(ps Process, w Writer) int {
+	return w.Write(uint64ToBytes(ps.Value()))
+}
+
+func uint64ToBytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+func Transfer(ps Process, to Reader, amount uint64) int {
+	if err := ps.Transfer(common.BytesToAddress(to.Read()), amount); err != nil {
+		ps.Logger().Debug("failed to execute Transfer", "err", err)
+		return -1
+	}
+	return 0
+}
+
 func ReadState(ps Process, key Reader, offset int, buf Writer) int {
 	v, err := ps.State().Get(key.Read())
 	if err != nil {
@@ -94,7 +147,9 @@ func ReadState(ps Process, key Reader, offset int, buf Writer) int {
 }
 
 func WriteState(ps Process, key, val Reader) int {
-	err := ps.State().Set(key.Read(), val.Read())
+	v := val.Read()
+	checkLimit("state value size", len(v), ps.Params().MaxValueSize)
+	err := ps.State().Set(key.Read(), v)
 	if err != nil {
 		ps.Logger().Debug("failed to execute WriteState", "err", err)
 		return -1
@@ -102,6 +157,44 @@ func WriteState(ps Process, key, val Reader) int {
 	return 0
 }
 
+func DeleteState(ps Process, key Reader) int {
+	err := ps.State().Delete(key.Read())
+	if err != nil {
+		ps.Logger().Debug("failed to execute DeleteState", "err", err)
+		return -1
+	}
+	return 0
+}
+
+// IterateState puts key-value pairs in [start, end) into the value table, and returns the id of them.
+// An empty start or end means an unbounded range.
+// The format of value is same as args: <elem_num>|<key1_size>|<key1>|<value1_size>|<value1>|...
+func IterateState(ps Process, start, end Reader, limit int) int {
+	kvs, err := ps.State().Iterate(bytesOrNil(start.Read()), bytesOrNil(end.Read()), limit)
+	if err != nil {
+		ps.Logger().Debug("failed to execute IterateState", "err", err)
+		return -1
+	}
+	var args Args
+	for _, kv := range kvs {
+		args.PushBytes(kv.Key)
+		args.PushBytes(kv.Value)
+	}
+	id, err := ps.ValueTable().Put(SerializeArgs(args))
+	if err != nil {
+		ps.Logger().Debug("failed to execute IterateState", "err", err)
+		return -1
+	}
+	return id
+}
+
+func bytesOrNil(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return b
+}
+
 func SetResponse(ps Process, val Reader) int {
 	ps.SetResponse(val.Read())
 	return 0
@@ -121,6 +214,86 @@ func CallContract(ps Process, addr, entry Reader, argb Reader) int {
 	return id
 }
 
+// CreateContract creates a new contract of the wasm module, and writes the address of it into ret.
+func CreateContract(ps Process, code, argb Reader, ret Writer) int {
+	args, err := DeserializeArgs(argb.Read())
+	if err != nil {
+		ps.Logger().Error("invalid argument format", "err", err)
+		return -1
+	}
+	addr, err := ps.CreateContract(code.Read(), args)
+	if err != nil {
+		ps.Logger().Debug("fail to execute CreateContract", "err", err)
+		return -1
+	}
+	return ret.Write(addr[:])
+}
+
+// CreateContractFromHash creates a new contract of the uploaded code, and writes the address of it into ret.
+func CreateContractFromHash(ps Process, hash, argb Reader, ret Writer) int {
+	h := hash.Read()
+	if len(h) != common.HashLength {
+		ps.Logger().Error("invalid code hash", "size", len(h))
+		return -1
+	}
+	args, err := DeserializeArgs(argb.Read())
+	if err != nil {
+		ps.Logger().Error("invalid argument format", "err", err)
+		return -1
+	}
+	addr, err := ps.CreateContractFromHash(common.BytesToHash(h), args)
+	if err != nil {
+		ps.Logger().Debug("fail to execute CreateContractFromHash", "err", err)
+		return -1
+	}
+	return ret.Write(addr[:])
+}
+
+// StaticCallContract calls the contract like CallContract, but the callee cannot modify any state
+func StaticCallContract(ps Process, addr, entry Reader, argb Reader) int {
+	args, err := DeserializeArgs(argb.Read())
+	if err != nil {
+		ps.Logger().Error("invalid argument format", "err", err)
+		return -1
+	}
+	id, err := ps.StaticCall(common.BytesToAddress(addr.Read()), entry.Read(), args)
+	if err != nil {
+		ps.Logger().Debug("fail to execute StaticCallContract", "err", err)
+		return -1
+	}
+	return id
+}
+
+func LockReentrancy(ps Process) int {
+	ps.LockReentrancy()
+	return 0
+}
+
+// GetCallError puts the error of the last call to other contract into the value table, and returns the id of it.
+// If the last call succeeded, this returns -1.
+// The format of value is <code: 4byte>|<message>
+func GetCallError(ps Process) int {
+	cerr := ps.CallError()
+	if cerr == nil {
+		return -1
+	}
+	id, err := ps.ValueTable().Put(cerr.Bytes())
+	if err != nil {
+		ps.Logger().Debug("failed to execute GetCallError", "err", err)
+		return -1
+	}
+	return id
+}
+
+// Revert aborts the whole transaction with the code. A zero code is replaced with 1 because it means success.
+func Revert(ps Process, code uint32, msg Reader) int {
+	if code == 0 {
+		code = 1
+	}
+	ps.Revert(code, msg.Read())
+	return 0
+}
+
 func Read(ps Process, id, offset int, buf Writer) int {
 	v, err := ps.Read(id)
 	if err != nil {
@@ -168,7 +341,10 @@ func EmitEvent(ps Process, name, value Reader) int {
 		ps.Logger().Debug("invalid event", "err", err)
 		return -1
 	}
-	ps.EmitEvent(ev)
+	if err := ps.EmitEvent(ev); err != nil {
+		ps.Logger().Debug("fail to execute EmitEvent", "err", err)
+		return -1
+	}
 	return 0
 }
 
@@ -4,11 +4,19 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
 	"github.com/bluele/hypermint/pkg/logger"
+	"github.com/bluele/hypermint/pkg/testutil"
 	"github.com/bluele/hypermint/pkg/util"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 )
 
 func TestKeccak256(t *testing.T) {
@@ -80,6 +88,163 @@ func TestSha256(t *testing.T) {
 	}
 }
 
+func TestBlockContext(t *testing.T) {
+	assert := assert.New(t)
+
+	proposer := common.BytesToAddress([]byte("proposer"))
+	txBytes := []byte("tx")
+	header := abci.Header{
+		ChainID:         "test-chain",
+		Height:          10,
+		Time:            time.Unix(1500000000, 0),
+		ProposerAddress: proposer.Bytes(),
+	}
+	ctx := sdk.NewContext(nil, header, false, nil).WithTxBytes(txBytes)
+	ps := NewProcess(&Env{Context: ctx}, nil, nil)
+
+	assert.EqualValues(10, GetBlockHeight(ps))
+	assert.EqualValues(1500000000, GetBlockTime(ps))
+
+	mem := make([]byte, 64)
+	w := NewWriter(mem, 0, 64)
+	assert.Equal(len(header.ChainID), GetChainID(ps, w))
+	assert.Equal([]byte(header.ChainID), mem[:len(header.ChainID)])
+
+	w = NewWriter(mem, 0, 20)
+	assert.Equal(20, GetProposer(ps, w))
+	assert.Equal(proposer.Bytes(), w.(Reader).Read())
+
+	w = NewWriter(mem, 0, 32)
+	assert.Equal(32, GetTxHash(ps, w))
+	assert.Equal(tmhash.Sum(txBytes), w.(Reader).Read())
+
+	// buffer is too short
+	assert.Equal(-1, GetTxHash(ps, NewWriter(mem, 0, 31)))
+}
+
+func TestNativeCoin(t *testing.T) {
+	assert := assert.New(t)
+
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	am := account.NewAccountMapper(key)
+
+	c := &Contract{Owner: common.BytesToAddress([]byte("owner")), Code: []byte("code")}
+	to := common.BytesToAddress([]byte("to"))
+	_, err = am.AddBalance(ctx, c.Address(), 100)
+	assert.NoError(err)
+	ps := NewProcess(&Env{Context: ctx, Contract: c, AccountMapper: am, Value: 30}, nil, nil)
+
+	mem := make([]byte, 28)
+	copy(mem, to.Bytes())
+	addr := NewReader(mem, 0, 20)
+	w := NewWriter(mem, 20, 8)
+
+	assert.Equal(8, GetValue(ps, w))
+	assert.Equal(uint64ToBytes(30), w.(Reader).Read())
+
+	assert.Equal(8, GetBalance(ps, addr, w))
+	assert.Equal(uint64ToBytes(0), w.(Reader).Read())
+
+	assert.Equal(0, Transfer(ps, addr, 40))
+	assert.Equal(8, GetBalance(ps, addr, w))
+	assert.Equal(uint64ToBytes(40), w.(Reader).Read())
+
+	// the contract cannot send coin over its balance
+	assert.Equal(-1, Transfer(ps, addr, 61))
+	b, err := am.GetBalance(ctx, c.Address())
+	assert.NoError(err)
+	assert.EqualValues(60, b)
+
+	// the balance of the contract is kept if the balance of the recipient overflows
+	_, err = am.AddBalance(ctx, to, math.MaxUint64-40)
+	assert.NoError(err)
+	assert.Equal(account.ErrBalanceOverflow, ps.Transfer(to, 1))
+	assert.Equal(-1, Transfer(ps, addr, 1))
+	b, err = am.GetBalance(ctx, c.Address())
+	assert.NoError(err)
+	assert.EqualValues(60, b)
+	b, err = am.GetBalance(ctx, to)
+	assert.NoError(err)
+	assert.EqualValues(uint64(math.MaxUint64), b)
+}
+
+func TestCreateContract(t *testing.T) {
+	assert := assert.New(t)
+
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	am := account.NewAccountMapper(key)
+	cm := NewContractMapper(key)
+	em := NewEnvManager(key, cm, am)
+
+	creator := &Contract{Owner: common.BytesToAddress([]byte("owner")), Code: []byte("code")}
+	ps := NewProcess(&Env{Context: ctx, Contract: creator, EnvManager: em, AccountMapper: am}, nil, nil)
+
+	code, _ := hex.DecodeString(testCode0)
+	failCode, _ := hex.DecodeString(testCodeFail)
+
+	var call = func(f func(ps Process, code, argb Reader, ret Writer) int, code []byte) (common.Address, int) {
+		var args Args
+		args.PushBytes([]byte("arg"))
+		ab := SerializeArgs(args)
+		mem := make([]byte, len(code)+len(ab)+20)
+		copy(mem, code)
+		copy(mem[len(code):], ab)
+		c := NewReader(mem, 0, int64(len(code)))
+		argb := NewReader(mem, int64(len(code)), int64(len(ab)))
+		w := NewWriter(mem, int64(len(mem)-20), 20)
+		ret := f(ps, c, argb, w)
+		return common.BytesToAddress(w.(Reader).Read()), ret
+	}
+	var create = func(code []byte) (common.Address, int) {
+		return call(CreateContract, code)
+	}
+	var createFromHash = func(hash []byte) (common.Address, int) {
+		return call(CreateContractFromHash, hash)
+	}
+
+	// create from the code
+	addr, ret := create(code)
+	assert.Equal(20, ret)
+	assert.Equal(NewContractAddress(creator.Address(), 0), addr)
+	c, err := cm.Get(ctx, addr)
+	assert.NoError(err)
+	assert.Equal(creator.Address(), c.Owner)
+	assert.Equal(code, c.Code)
+
+	// create from the code hash
+	h := cm.PutCode(ctx, code)
+	addr, ret = createFromHash(h[:])
+	assert.Equal(20, ret)
+	assert.Equal(NewContractAddress(creator.Address(), 1), addr)
+	assert.True(cm.Has(ctx, addr))
+	// a hash is never taken as a code, and vice versa
+	_, ret = create(h[:])
+	assert.Equal(-1, ret)
+	_, ret = createFromHash(code)
+	assert.Equal(-1, ret)
+
+	// unknown code hash
+	_, ret = createFromHash(CodeHash([]byte("unknown")).Bytes())
+	assert.Equal(-1, ret)
+	// invalid code
+	_, ret = create([]byte("invalid code"))
+	assert.Equal(-1, ret)
+
+	// the contract is not created if init fails
+	_, ret = create(failCode)
+	assert.Equal(-1, ret)
+	assert.False(cm.Has(ctx, NewContractAddress(creator.Address(), 2)))
+	nonce, err := am.GetNonce(ctx, creator.Address())
+	assert.NoError(err)
+	assert.EqualValues(2, nonce)
+}
+
 type mockProcess struct {
 	Process
 }
@@ -0,0 +1,84 @@
+package contract
+
+import (
+	"bytes"
+	"fmt"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/contract/interp"
+	"github.com/go-interpreter/wagon/wasm"
+)
+
+// InterpEngine runs contracts on the interpreter of the interp package.
+// It charges GasPerInstruction for every wasm instruction, so its gas differs from LifeEngine's.
+type InterpEngine struct{}
+
+var _ Engine = (*InterpEngine)(nil)
+
+// NewInterpEngine returns an engine which decodes the code on every execution
+func NewInterpEngine() *InterpEngine {
+	return &InterpEngine{}
+}
+
+func (e *InterpEngine) Name() string {
+	return EngineInterp
+}
+
+func (e *InterpEngine) Instantiate(code []byte, hfs HostFunctions, params VMParams) (Instance, error) {
+	m, err := interp.Decode(code)
+	if err != nil {
+		return nil, err
+	}
+	in, err := interp.NewInstance(m, interpResolver{hfs: hfs}, interp.Config{
+		MaxMemoryPages:    params.MaxMemoryPages,
+		MaxCallStack:      int(params.MaxCallStack),
+		GasPerInstruction: GasPerInstruction,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &interpInstance{Instance: in, code: code}, nil
+}
+
+// interpResolver resolves the imports of interp with the host functions
+type interpResolver struct {
+	hfs HostFunctions
+}
+
+func (r interpResolver) Resolve(module, field string) (interp.HostFunction, error) {
+	f, err := r.hfs.Resolve(module, field)
+	if err != nil {
+		return nil, err
+	}
+	return interp.HostFunction(f), nil
+}
+
+// interpInstance is an instance of interp
+type interpInstance struct {
+	*interp.Instance
+	code []byte
+}
+
+var _ Instance = (*interpInstance)(nil)
+
+func (i *interpInstance) Call(meter sdk.GasMeter, entry string, params ...int64) (int64, error) {
+	ret, err := i.Instance.Call(meter, entry, params...)
+	if err == interp.ErrExportNotFound {
+		return 0, ErrEntryNotFound
+	}
+	return ret, err
+}
+
+// StackTrace returns the call stack in the format of LifeEngine
+func (i *interpInstance) StackTrace() []string {
+	names := make(map[int]string)
+	if m, err := wasm.DecodeModule(bytes.NewReader(i.code)); err == nil {
+		names = functionNames(m)
+	}
+	var trace []string
+	frames := i.CallStack()
+	for j := len(frames) - 1; j >= 0; j-- {
+		trace = append(trace, fmt.Sprintf("<%d> [%d] %s", j, frames[j], names[int(frames[j])]))
+	}
+	return trace
+}
@@ -0,0 +1,306 @@
+package interp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/go-interpreter/wagon/wasm"
+	"github.com/go-interpreter/wagon/wasm/leb128"
+)
+
+// opcodes of the integer instructions of the wasm MVP
+const (
+	opUnreachable  = 0x00
+	opNop          = 0x01
+	opBlock        = 0x02
+	opLoop         = 0x03
+	opIf           = 0x04
+	opElse         = 0x05
+	opEnd          = 0x0b
+	opBr           = 0x0c
+	opBrIf         = 0x0d
+	opBrTable      = 0x0e
+	opReturn       = 0x0f
+	opCall         = 0x10
+	opCallIndirect = 0x11
+	opDrop         = 0x1a
+	opSelect       = 0x1b
+	opGetLocal     = 0x20
+	opSetLocal     = 0x21
+	opTeeLocal     = 0x22
+	opGetGlobal    = 0x23
+	opSetGlobal    = 0x24
+
+	opI32Load    = 0x28
+	opI64Load    = 0x29
+	opI32Load8S  = 0x2c
+	opI32Load8U  = 0x2d
+	opI32Load16S = 0x2e
+	opI32Load16U = 0x2f
+	opI64Load8S  = 0x30
+	opI64Load8U  = 0x31
+	opI64Load16S = 0x32
+	opI64Load16U = 0x33
+	opI64Load32S = 0x34
+	opI64Load32U = 0x35
+	opI32Store   = 0x36
+	opI64Store   = 0x37
+	opI32Store8  = 0x3a
+	opI32Store16 = 0x3b
+	opI64Store8  = 0x3c
+	opI64Store16 = 0x3d
+	opI64Store32 = 0x3e
+	opMemorySize = 0x3f
+	opMemoryGrow = 0x40
+	opI32Const   = 0x41
+	opI64Const   = 0x42
+
+	opI32Eqz = 0x45
+	opI32Eq  = 0x46
+	opI32Ne  = 0x47
+	opI32LtS = 0x48
+	opI32LtU = 0x49
+	opI32GtS = 0x4a
+	opI32GtU = 0x4b
+	opI32LeS = 0x4c
+	opI32LeU = 0x4d
+	opI32GeS = 0x4e
+	opI32GeU = 0x4f
+	opI64Eqz = 0x50
+	opI64Eq  = 0x51
+	opI64Ne  = 0x52
+	opI64LtS = 0x53
+	opI64LtU = 0x54
+	opI64GtS = 0x55
+	opI64GtU = 0x56
+	opI64LeS = 0x57
+	opI64LeU = 0x58
+	opI64GeS = 0x59
+	opI64GeU = 0x5a
+
+	opI32Clz    = 0x67
+	opI32Ctz    = 0x68
+	opI32Popcnt = 0x69
+	opI32Add    = 0x6a
+	opI32Sub    = 0x6b
+	opI32Mul    = 0x6c
+	opI32DivS   = 0x6d
+	opI32DivU   = 0x6e
+	opI32RemS   = 0x6f
+	opI32RemU   = 0x70
+	opI32And    = 0x71
+	opI32Or     = 0x72
+	opI32Xor    = 0x73
+	opI32Shl    = 0x74
+	opI32ShrS   = 0x75
+	opI32ShrU   = 0x76
+	opI32Rotl   = 0x77
+	opI32Rotr   = 0x78
+	opI64Clz    = 0x79
+	opI64Ctz    = 0x7a
+	opI64Popcnt = 0x7b
+	opI64Add    = 0x7c
+	opI64Sub    = 0x7d
+	opI64Mul    = 0x7e
+	opI64DivS   = 0x7f
+	opI64DivU   = 0x80
+	opI64RemS   = 0x81
+	opI64RemU   = 0x82
+	opI64And    = 0x83
+	opI64Or     = 0x84
+	opI64Xor    = 0x85
+	opI64Shl    = 0x86
+	opI64ShrS   = 0x87
+	opI64ShrU   = 0x88
+	opI64Rotl   = 0x89
+	opI64Rotr   = 0x8a
+
+	opI32WrapI64    = 0xa7
+	opI64ExtendSI32 = 0xac
+	opI64ExtendUI32 = 0xad
+)
+
+// block types of the wasm MVP
+const (
+	blockTypeEmpty = 0x40
+	blockTypeI32   = 0x7f
+	blockTypeI64   = 0x7e
+)
+
+// control is a block which is being decoded
+type control struct {
+	start   int
+	hasElse bool
+	elseAt  int
+}
+
+// decodeFunction decodes the body of the function, whose last end is removed by the wasm parser.
+// The ends of blocks and the depths of branches are resolved here, so the interpreter doesn't check them.
+func (m *Module) decodeFunction(fn *function, body wasm.FunctionBody) error {
+	n := len(fn.typ.params)
+	for _, l := range body.Locals {
+		if hasFloat(l.Type) {
+			return errFloat
+		}
+		n += int(l.Count)
+		if n > MaxLocals {
+			return fmt.Errorf("the number of locals exceeds the limit %v", MaxLocals)
+		}
+	}
+	fn.numLocals = n - len(fn.typ.params)
+
+	r := bytes.NewReader(body.Code)
+	var ctrls []control
+	for r.Len() > 0 {
+		op, _ := r.ReadByte()
+		in := instr{op: op}
+		var err error
+		switch op {
+		case opUnreachable, opNop, opReturn, opDrop, opSelect:
+		case opBlock, opLoop, opIf:
+			var bt byte
+			if bt, err = r.ReadByte(); err != nil {
+				return err
+			}
+			switch bt {
+			case blockTypeEmpty:
+			case blockTypeI32, blockTypeI64:
+				in.imm = 1
+			default:
+				return fmt.Errorf("unsupported block type: %#x", bt)
+			}
+			ctrls = append(ctrls, control{start: len(fn.code)})
+		case opElse:
+			if len(ctrls) == 0 || fn.code[ctrls[len(ctrls)-1].start].op != opIf || ctrls[len(ctrls)-1].hasElse {
+				return errors.New("else without if")
+			}
+			c := &ctrls[len(ctrls)-1]
+			c.hasElse, c.elseAt = true, len(fn.code)
+		case opEnd:
+			if len(ctrls) == 0 {
+				return errors.New("instructions after the end of the function")
+			}
+			c := ctrls[len(ctrls)-1]
+			ctrls = ctrls[:len(ctrls)-1]
+			end := uint32(len(fn.code))
+			fn.code[c.start].a = end
+			if c.hasElse {
+				fn.code[c.start].b = uint32(c.elseAt)
+				fn.code[c.elseAt].a = end
+			}
+		case opBr, opBrIf:
+			if in.a, err = leb128.ReadVarUint32(r); err != nil {
+				return err
+			}
+			if int(in.a) > len(ctrls) {
+				return fmt.Errorf("invalid branch depth: %v", in.a)
+			}
+		case opBrTable:
+			var count uint32
+			if count, err = leb128.ReadVarUint32(r); err != nil {
+				return err
+			}
+			if int(count) > r.Len() {
+				return errors.New("invalid br_table")
+			}
+			targets := make([]uint32, count+1)
+			for i := range targets {
+				if targets[i], err = leb128.ReadVarUint32(r); err != nil {
+					return err
+				}
+				if int(targets[i]) > len(ctrls) {
+					return fmt.Errorf("invalid branch depth: %v", targets[i])
+				}
+			}
+			in.a = uint32(len(fn.tables))
+			fn.tables = append(fn.tables, targets)
+		case opCall:
+			if in.a, err = leb128.ReadVarUint32(r); err != nil {
+				return err
+			}
+			if int(in.a) >= m.numFuncs() {
+				return fmt.Errorf("invalid function index: %v", in.a)
+			}
+		case opCallIndirect:
+			if in.a, err = leb128.ReadVarUint32(r); err != nil {
+				return err
+			}
+			if _, err = m.funcType(in.a); err != nil {
+				return err
+			}
+			if m.table == nil {
+				return errors.New("call_indirect without table")
+			}
+			if _, err = leb128.ReadVarUint32(r); err != nil {
+				return err
+			}
+		case opGetLocal, opSetLocal, opTeeLocal:
+			if in.a, err = leb128.ReadVarUint32(r); err != nil {
+				return err
+			}
+			if int(in.a) >= n {
+				return fmt.Errorf("invalid local index: %v", in.a)
+			}
+		case opGetGlobal, opSetGlobal:
+			if in.a, err = leb128.ReadVarUint32(r); err != nil {
+				return err
+			}
+			if int(in.a) >= len(m.globals) {
+				return fmt.Errorf("invalid global index: %v", in.a)
+			}
+			if op == opSetGlobal && !m.globals[in.a].mutable {
+				return fmt.Errorf("global %v is immutable", in.a)
+			}
+		case opI32Load, opI64Load, opI32Load8S, opI32Load8U, opI32Load16S, opI32Load16U,
+			opI64Load8S, opI64Load8U, opI64Load16S, opI64Load16U, opI64Load32S, opI64Load32U,
+			opI32Store, opI64Store, opI32Store8, opI32Store16, opI64Store8, opI64Store16, opI64Store32:
+			if !m.hasMemory {
+				return errors.New("memory instruction without memory")
+			}
+			// the alignment is a hint which doesn't change the semantics
+			if _, err = leb128.ReadVarUint32(r); err != nil {
+				return err
+			}
+			if in.a, err = leb128.ReadVarUint32(r); err != nil {
+				return err
+			}
+		case opMemorySize, opMemoryGrow:
+			if !m.hasMemory {
+				return errors.New("memory instruction without memory")
+			}
+			if _, err = leb128.ReadVarUint32(r); err != nil {
+				return err
+			}
+		case opI32Const:
+			var v int32
+			if v, err = leb128.ReadVarint32(r); err != nil {
+				return err
+			}
+			in.imm = uint64(uint32(v))
+		case opI64Const:
+			var v int64
+			if v, err = leb128.ReadVarint64(r); err != nil {
+				return err
+			}
+			in.imm = uint64(v)
+		default:
+			if !isNumeric(op) {
+				return fmt.Errorf("unsupported opcode: %#x", op)
+			}
+		}
+		fn.code = append(fn.code, in)
+	}
+	if len(ctrls) != 0 {
+		return errors.New("block without end")
+	}
+	fn.code = append(fn.code, instr{op: opEnd})
+	return nil
+}
+
+// isNumeric returns true if the op is an integer instruction which has no immediate
+func isNumeric(op byte) bool {
+	return (op >= opI32Eqz && op <= opI64GeU) ||
+		(op >= opI32Clz && op <= opI64Rotr) ||
+		op == opI32WrapI64 || op == opI64ExtendSI32 || op == opI64ExtendUI32
+}
@@ -0,0 +1,628 @@
+package interp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/go-interpreter/wagon/wasm"
+)
+
+var (
+	ErrExportNotFound        = errors.New("export not found")
+	ErrUnreachable           = errors.New("unreachable executed")
+	ErrOutOfBoundsMemory     = errors.New("out of bounds memory access")
+	ErrDivideByZero          = errors.New("integer divide by zero")
+	ErrIntegerOverflow       = errors.New("integer overflow")
+	ErrUndefinedElement      = errors.New("undefined element")
+	ErrSignatureMismatch     = errors.New("indirect call signature mismatch")
+	ErrCallStackExceeded     = errors.New("max call stack depth exceeded")
+	ErrMemoryExceeded        = errors.New("max memory exceeded")
+	ErrInvalidArgumentsCount = errors.New("invalid number of arguments")
+)
+
+// errGasExhausted stops the execution when the instructions use up the gas
+var errGasExhausted = errors.New("gas exhausted")
+
+const gasDescInstruction = "WasmInstruction"
+
+// HostFunction is a function which modules import.
+// mem is the linear memory of the caller, and args are the arguments of the call.
+type HostFunction func(mem []byte, args []int64) int64
+
+// Resolver resolves the functions which modules import
+type Resolver interface {
+	Resolve(module, field string) (HostFunction, error)
+}
+
+// Config is the limits and the gas cost of an instance
+type Config struct {
+	// MaxMemoryPages is the max number of memory pages
+	MaxMemoryPages uint32
+	// MaxCallStack is the max depth of the call stack of wasm functions
+	MaxCallStack int
+	// GasPerInstruction is the gas cost of executing an instruction
+	GasPerInstruction uint64
+}
+
+type label struct {
+	// cont is the instruction which a branch to the label continues with
+	cont   int
+	height int
+	arity  int
+	loop   bool
+}
+
+// Instance is an instance of a module. It has its own memory, globals and table.
+type Instance struct {
+	module   *Module
+	config   Config
+	hfs      []HostFunction
+	mem      []byte
+	maxPages uint32
+	globals  []uint64
+	table    []uint32
+
+	stack  []uint64
+	labels []label
+	frames []uint32
+
+	meter     sdk.GasMeter
+	remaining uint64
+	pending   uint64
+}
+
+// NewInstance instantiates the module. The imports of the module are resolved with r.
+func NewInstance(m *Module, r Resolver, config Config) (*Instance, error) {
+	if m.memPages > config.MaxMemoryPages {
+		return nil, ErrMemoryExceeded
+	}
+	in := &Instance{
+		module:   m,
+		config:   config,
+		mem:      make([]byte, int(m.memPages)*PageSize),
+		maxPages: config.MaxMemoryPages,
+		globals:  make([]uint64, len(m.globals)),
+		table:    append([]uint32(nil), m.table...),
+	}
+	if m.maxPages < in.maxPages {
+		in.maxPages = m.maxPages
+	}
+	for _, imp := range m.imports {
+		f, err := r.Resolve(imp.module, imp.field)
+		if err != nil {
+			return nil, err
+		}
+		in.hfs = append(in.hfs, f)
+	}
+	for i, g := range m.globals {
+		in.globals[i] = g.value
+	}
+	for _, d := range m.data {
+		if uint64(d.offset)+uint64(len(d.data)) > uint64(len(in.mem)) {
+			return nil, errors.New("data segment is out of the memory")
+		}
+		copy(in.mem[d.offset:], d.data)
+	}
+	return in, nil
+}
+
+// Memory returns the linear memory of the instance
+func (in *Instance) Memory() []byte {
+	return in.mem
+}
+
+// CallStack returns the indexes of the functions in the call stack. The last one is the innermost.
+// After a trap, it is the call stack at the trap.
+func (in *Instance) CallStack() []uint32 {
+	return in.frames
+}
+
+// Call calls the exported function, and charges the gas consumed by the instructions to the meter.
+// If the meter runs out of gas, this panics with sdk.ErrorOutOfGas.
+// A panic in host functions stops the execution, and is returned as an error.
+func (in *Instance) Call(meter sdk.GasMeter, name string, params ...int64) (int64, error) {
+	idx, ok := in.module.exports[name]
+	if !ok {
+		return 0, ErrExportNotFound
+	}
+	typ := in.module.typeOf(idx)
+	if len(params) != len(typ.params) {
+		return 0, ErrInvalidArgumentsCount
+	}
+	in.stack, in.labels, in.frames = in.stack[:0], in.labels[:0], in.frames[:0]
+	for i, p := range params {
+		in.push(normalize(typ.params[i], uint64(p)))
+	}
+	in.meter = meter
+	in.remaining = meter.Limit() - meter.GasConsumed()
+	in.pending = 0
+
+	ret, err := in.run(idx)
+	if err == errGasExhausted {
+		meter.ConsumeGas(in.remaining+1, gasDescInstruction)
+	}
+	meter.ConsumeGas(in.pending, gasDescInstruction)
+	return ret, err
+}
+
+func (in *Instance) run(idx uint32) (ret int64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case error:
+				ret, err = -1, e
+			default:
+				ret, err = -1, fmt.Errorf("%v", e)
+			}
+		}
+	}()
+	in.call(idx)
+	typ := in.module.typeOf(idx)
+	if len(typ.results) == 0 {
+		return 0, nil
+	}
+	v := in.pop()
+	if typ.results[0] == wasm.ValueTypeI32 {
+		return int64(int32(v)), nil
+	}
+	return int64(v), nil
+}
+
+// normalize keeps the upper bits of i32 values zero
+func normalize(t wasm.ValueType, v uint64) uint64 {
+	if t == wasm.ValueTypeI32 {
+		return uint64(uint32(v))
+	}
+	return v
+}
+
+func (in *Instance) push(v uint64) {
+	in.stack = append(in.stack, v)
+}
+
+func (in *Instance) pop() uint64 {
+	n := len(in.stack) - 1
+	v := in.stack[n]
+	in.stack = in.stack[:n]
+	return v
+}
+
+func (in *Instance) push32(v uint32) {
+	in.push(uint64(v))
+}
+
+func (in *Instance) pop32() uint32 {
+	return uint32(in.pop())
+}
+
+func (in *Instance) pushBool(b bool) {
+	if b {
+		in.push(1)
+	} else {
+		in.push(0)
+	}
+}
+
+func (in *Instance) useGas() {
+	in.pending += in.config.GasPerInstruction
+	if in.pending > in.remaining {
+		panic(errGasExhausted)
+	}
+}
+
+// unwind removes the values of the block except for the results
+func (in *Instance) unwind(height, arity int) {
+	copy(in.stack[height:], in.stack[len(in.stack)-arity:])
+	in.stack = in.stack[:height+arity]
+}
+
+// branch unwinds the blocks up to the label of given depth, and returns the instruction to continue with
+func (in *Instance) branch(depth uint32) int {
+	i := len(in.labels) - 1 - int(depth)
+	l := in.labels[i]
+	in.unwind(l.height, l.arity)
+	if l.loop {
+		in.labels = in.labels[:i+1]
+	} else {
+		in.labels = in.labels[:i]
+	}
+	return l.cont
+}
+
+func (in *Instance) call(idx uint32) {
+	if int(idx) < len(in.hfs) {
+		in.callHost(idx)
+		return
+	}
+	if len(in.frames) >= in.config.MaxCallStack {
+		panic(ErrCallStackExceeded)
+	}
+	in.frames = append(in.frames, idx)
+	in.exec(in.module.funcs[int(idx)-len(in.hfs)])
+	in.frames = in.frames[:len(in.frames)-1]
+}
+
+func (in *Instance) callHost(idx uint32) {
+	typ := in.module.imports[idx].typ
+	args := make([]int64, len(typ.params))
+	for i := len(args) - 1; i >= 0; i-- {
+		args[i] = int64(in.pop())
+	}
+	// host functions may consume the gas of the meter
+	in.meter.ConsumeGas(in.pending, gasDescInstruction)
+	in.pending = 0
+	ret := in.hfs[idx](in.mem, args)
+	in.remaining = in.meter.Limit() - in.meter.GasConsumed()
+	if len(typ.results) != 0 {
+		in.push(normalize(typ.results[0], uint64(ret)))
+	}
+}
+
+// addr pops the address of a memory access, and returns the effective address
+func (in *Instance) addr(offset uint32, size uint64) uint64 {
+	ea := uint64(in.pop32()) + uint64(offset)
+	if ea+size > uint64(len(in.mem)) {
+		panic(ErrOutOfBoundsMemory)
+	}
+	return ea
+}
+
+func (in *Instance) exec(fn *function) {
+	locals := make([]uint64, len(fn.typ.params)+fn.numLocals)
+	for i := len(fn.typ.params) - 1; i >= 0; i-- {
+		locals[i] = in.pop()
+	}
+	// the label of the function is the target of return
+	base := len(in.labels)
+	in.labels = append(in.labels, label{cont: len(fn.code), height: len(in.stack), arity: len(fn.typ.results)})
+
+	code := fn.code
+	for pc := 0; pc < len(code); {
+		ins := &code[pc]
+		pc++
+		in.useGas()
+		switch ins.op {
+		case opUnreachable:
+			panic(ErrUnreachable)
+		case opNop:
+		case opBlock:
+			in.labels = append(in.labels, label{cont: int(ins.a) + 1, height: len(in.stack), arity: int(ins.imm)})
+		case opLoop:
+			in.labels = append(in.labels, label{cont: pc, height: len(in.stack), loop: true})
+		case opIf:
+			cond := in.pop32()
+			in.labels = append(in.labels, label{cont: int(ins.a) + 1, height: len(in.stack), arity: int(ins.imm)})
+			if cond == 0 {
+				if ins.b != 0 {
+					pc = int(ins.b) + 1
+				} else {
+					in.labels = in.labels[:len(in.labels)-1]
+					pc = int(ins.a) + 1
+				}
+			}
+		case opElse:
+			// the end of the then branch
+			l := in.labels[len(in.labels)-1]
+			in.labels = in.labels[:len(in.labels)-1]
+			in.unwind(l.height, l.arity)
+			pc = int(ins.a) + 1
+		case opEnd:
+			l := in.labels[len(in.labels)-1]
+			in.labels = in.labels[:len(in.labels)-1]
+			in.unwind(l.height, l.arity)
+		case opBr:
+			pc = in.branch(ins.a)
+		case opBrIf:
+			if in.pop32() != 0 {
+				pc = in.branch(ins.a)
+			}
+		case opBrTable:
+			targets := fn.tables[ins.a]
+			i := in.pop32()
+			if int(i) >= len(targets)-1 {
+				i = uint32(len(targets) - 1)
+			}
+			pc = in.branch(targets[i])
+		case opReturn:
+			pc = in.branch(uint32(len(in.labels) - 1 - base))
+		case opCall:
+			in.call(ins.a)
+		case opCallIndirect:
+			i := in.pop32()
+			if int(i) >= len(in.table) || in.table[i] == noElem {
+				panic(ErrUndefinedElement)
+			}
+			idx := in.table[i]
+			if !in.module.typeOf(idx).equal(in.module.types[ins.a]) {
+				panic(ErrSignatureMismatch)
+			}
+			in.call(idx)
+		case opDrop:
+			in.pop()
+		case opSelect:
+			cond := in.pop32()
+			v2, v1 := in.pop(), in.pop()
+			if cond != 0 {
+				in.push(v1)
+			} else {
+				in.push(v2)
+			}
+		case opGetLocal:
+			in.push(locals[ins.a])
+		case opSetLocal:
+			locals[ins.a] = in.pop()
+		case opTeeLocal:
+			locals[ins.a] = in.stack[len(in.stack)-1]
+		case opGetGlobal:
+			in.push(in.globals[ins.a])
+		case opSetGlobal:
+			in.globals[ins.a] = in.pop()
+
+		case opI32Load:
+			ea := in.addr(ins.a, 4)
+			in.push32(binary.LittleEndian.Uint32(in.mem[ea:]))
+		case opI64Load:
+			ea := in.addr(ins.a, 8)
+			in.push(binary.LittleEndian.Uint64(in.mem[ea:]))
+		case opI32Load8S:
+			ea := in.addr(ins.a, 1)
+			in.push32(uint32(int32(int8(in.mem[ea]))))
+		case opI32Load8U:
+			ea := in.addr(ins.a, 1)
+			in.push32(uint32(in.mem[ea]))
+		case opI32Load16S:
+			ea := in.addr(ins.a, 2)
+			in.push32(uint32(int32(int16(binary.LittleEndian.Uint16(in.mem[ea:])))))
+		case opI32Load16U:
+			ea := in.addr(ins.a, 2)
+			in.push32(uint32(binary.LittleEndian.Uint16(in.mem[ea:])))
+		case opI64Load8S:
+			ea := in.addr(ins.a, 1)
+			in.push(uint64(int64(int8(in.mem[ea]))))
+		case opI64Load8U:
+			ea := in.addr(ins.a, 1)
+			in.push(uint64(in.mem[ea]))
+		case opI64Load16S:
+			ea := in.addr(ins.a, 2)
+			in.push(uint64(int64(int16(binary.LittleEndian.Uint16(in.mem[ea:])))))
+		case opI64Load16U:
+			ea := in.addr(ins.a, 2)
+			in.push(uint64(binary.LittleEndian.Uint16(in.mem[ea:])))
+		case opI64Load32S:
+			ea := in.addr(ins.a, 4)
+			in.push(uint64(int64(int32(binary.LittleEndian.Uint32(in.mem[ea:])))))
+		case opI64Load32U:
+			ea := in.addr(ins.a, 4)
+			in.push(uint64(binary.LittleEndian.Uint32(in.mem[ea:])))
+		case opI32Store, opI64Store32:
+			v := in.pop32()
+			ea := in.addr(ins.a, 4)
+			binary.LittleEndian.PutUint32(in.mem[ea:], v)
+		case opI64Store:
+			v := in.pop()
+			ea := in.addr(ins.a, 8)
+			binary.LittleEndian.PutUint64(in.mem[ea:], v)
+		case opI32Store8, opI64Store8:
+			v := in.pop()
+			ea := in.addr(ins.a, 1)
+			in.mem[ea] = byte(v)
+		case opI32Store16, opI64Store16:
+			v := in.pop()
+			ea := in.addr(ins.a, 2)
+			binary.LittleEndian.PutUint16(in.mem[ea:], uint16(v))
+		case opMemorySize:
+			in.push32(uint32(len(in.mem) / PageSize))
+		case opMemoryGrow:
+			n := in.pop32()
+			cur := uint32(len(in.mem) / PageSize)
+			if uint64(cur)+uint64(n) > uint64(in.maxPages) {
+				// -1 means the failure
+				in.push32(math.MaxUint32)
+			} else {
+				in.mem = append(in.mem, make([]byte, int(n)*PageSize)...)
+				in.push32(cur)
+			}
+		case opI32Const, opI64Const:
+			in.push(ins.imm)
+
+		case opI32Eqz:
+			in.pushBool(in.pop32() == 0)
+		case opI64Eqz:
+			in.pushBool(in.pop() == 0)
+		case opI32Eq, opI32Ne, opI32LtS, opI32LtU, opI32GtS, opI32GtU, opI32LeS, opI32LeU, opI32GeS, opI32GeU:
+			b := in.pop32()
+			a := in.pop32()
+			in.pushBool(compare32(ins.op, a, b))
+		case opI64Eq, opI64Ne, opI64LtS, opI64LtU, opI64GtS, opI64GtU, opI64LeS, opI64LeU, opI64GeS, opI64GeU:
+			b := in.pop()
+			a := in.pop()
+			in.pushBool(compare64(ins.op, a, b))
+
+		case opI32Clz:
+			in.push32(uint32(bits.LeadingZeros32(in.pop32())))
+		case opI32Ctz:
+			in.push32(uint32(bits.TrailingZeros32(in.pop32())))
+		case opI32Popcnt:
+			in.push32(uint32(bits.OnesCount32(in.pop32())))
+		case opI64Clz:
+			in.push(uint64(bits.LeadingZeros64(in.pop())))
+		case opI64Ctz:
+			in.push(uint64(bits.TrailingZeros64(in.pop())))
+		case opI64Popcnt:
+			in.push(uint64(bits.OnesCount64(in.pop())))
+		case opI32Add, opI32Sub, opI32Mul, opI32DivS, opI32DivU, opI32RemS, opI32RemU,
+			opI32And, opI32Or, opI32Xor, opI32Shl, opI32ShrS, opI32ShrU, opI32Rotl, opI32Rotr:
+			b := in.pop32()
+			a := in.pop32()
+			in.push32(binop32(ins.op, a, b))
+		case opI64Add, opI64Sub, opI64Mul, opI64DivS, opI64DivU, opI64RemS, opI64RemU,
+			opI64And, opI64Or, opI64Xor, opI64Shl, opI64ShrS, opI64ShrU, opI64Rotl, opI64Rotr:
+			b := in.pop()
+			a := in.pop()
+			in.push(binop64(ins.op, a, b))
+
+		case opI32WrapI64:
+			in.push32(uint32(in.pop()))
+		case opI64ExtendSI32:
+			in.push(uint64(int64(int32(in.pop32()))))
+		case opI64ExtendUI32:
+			in.push(uint64(in.pop32()))
+		default:
+			panic(fmt.Errorf("unsupported opcode: %#x", ins.op))
+		}
+	}
+}
+
+func compare32(op byte, a, b uint32) bool {
+	switch op {
+	case opI32Eq:
+		return a == b
+	case opI32Ne:
+		return a != b
+	case opI32LtS:
+		return int32(a) < int32(b)
+	case opI32LtU:
+		return a < b
+	case opI32GtS:
+		return int32(a) > int32(b)
+	case opI32GtU:
+		return a > b
+	case opI32LeS:
+		return int32(a) <= int32(b)
+	case opI32LeU:
+		return a <= b
+	case opI32GeS:
+		return int32(a) >= int32(b)
+	default:
+		return a >= b
+	}
+}
+
+func compare64(op byte, a, b uint64) bool {
+	switch op {
+	case opI64Eq:
+		return a == b
+	case opI64Ne:
+		return a != b
+	case opI64LtS:
+		return int64(a) < int64(b)
+	case opI64LtU:
+		return a < b
+	case opI64GtS:
+		return int64(a) > int64(b)
+	case opI64GtU:
+		return a > b
+	case opI64LeS:
+		return int64(a) <= int64(b)
+	case opI64LeU:
+		return a <= b
+	case opI64GeS:
+		return int64(a) >= int64(b)
+	default:
+		return a >= b
+	}
+}
+
+func binop32(op byte, a, b uint32) uint32 {
+	switch op {
+	case opI32Add:
+		return a + b
+	case opI32Sub:
+		return a - b
+	case opI32Mul:
+		return a * b
+	case opI32DivS:
+		if b == 0 {
+			panic(ErrDivideByZero)
+		}
+		if int32(a) == math.MinInt32 && int32(b) == -1 {
+			panic(ErrIntegerOverflow)
+		}
+		return uint32(int32(a) / int32(b))
+	case opI32DivU:
+		if b == 0 {
+			panic(ErrDivideByZero)
+		}
+		return a / b
+	case opI32RemS:
+		if b == 0 {
+			panic(ErrDivideByZero)
+		}
+		return uint32(int32(a) % int32(b))
+	case opI32RemU:
+		if b == 0 {
+			panic(ErrDivideByZero)
+		}
+		return a % b
+	case opI32And:
+		return a & b
+	case opI32Or:
+		return a | b
+	case opI32Xor:
+		return a ^ b
+	case opI32Shl:
+		return a << (b & 31)
+	case opI32ShrS:
+		return uint32(int32(a) >> (b & 31))
+	case opI32ShrU:
+		return a >> (b & 31)
+	case opI32Rotl:
+		return bits.RotateLeft32(a, int(b&31))
+	default:
+		return bits.RotateLeft32(a, -int(b&31))
+	}
+}
+
+func binop64(op byte, a, b uint64) uint64 {
+	switch op {
+	case opI64Add:
+		return a + b
+	case opI64Sub:
+		return a - b
+	case opI64Mul:
+		return a * b
+	case opI64DivS:
+		if b == 0 {
+			panic(ErrDivideByZero)
+		}
+		if int64(a) == math.MinInt64 && int64(b) == -1 {
+			panic(ErrIntegerOverflow)
+		}
+		return uint64(int64(a) / int64(b))
+	case opI64DivU:
+		if b == 0 {
+			panic(ErrDivideByZero)
+		}
+		return a / b
+	case opI64RemS:
+		if b == 0 {
+			panic(ErrDivideByZero)
+		}
+		return uint64(int64(a) % int64(b))
+	case opI64RemU:
+		if b == 0 {
+			panic(ErrDivideByZero)
+		}
+		return a % b
+	case opI64And:
+		return a & b
+	case opI64Or:
+		return a | b
+	case opI64Xor:
+		return a ^ b
+	case opI64Shl:
+		return a << (b & 63)
+	case opI64ShrS:
+		return uint64(int64(a) >> (b & 63))
+	case opI64ShrU:
+		return a >> (b & 63)
+	case opI64Rotl:
+		return bits.RotateLeft64(a, int(b&63))
+	default:
+		return bits.RotateLeft64(a, -int(b&63))
+	}
+}
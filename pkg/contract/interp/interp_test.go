@@ -0,0 +1,159 @@
+package interp
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCode imports env.host(i32, i32) i32, and exports sum, fib, classify, ifelse, indirect, grow, store, load, div, callhost, counter, mul64 and trap.
+// The table is [sum, ifelse, host, undefined].
+const testCode = "0061736d0100000001160460017f017f60027f7f017f6000017f60027e7e017e020c0103656e7604686f73740001030e0d0000000000000000000002030204040170000405030100010606017f0141000b076c0d0373756d000103666962000208636c6173736966790003066966656c7365000408696e64697265637400050467726f7700060573746f72650007046c6f616400080364697600090863616c6c686f7374000a07636f756e746572000b056d756c3634000c0474726170000d0909010041000b030104000abc010d1c01017f02400340200120006a2101200041016b22000d000b0b20010b1c002000410248044020000f0b200041016b1002200041026b10026a0b1a0002400240024020000e020001020b410a0f0b41140f0b411e0b10002000047f41010541020b41e4006a0b0900410320001100000b0600200040000b0e004108200036020041082c00000b070020002802000b0b0041808080807820006d0b08002000410510000b0b00230041016a240023000b0700200020017e0b0300000b"
+
+type testResolver map[string]HostFunction
+
+func (r testResolver) Resolve(module, field string) (HostFunction, error) {
+	f, ok := r[module+"."+field]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return f, nil
+}
+
+func newTestInstance(t *testing.T, host HostFunction) *Instance {
+	code, err := hex.DecodeString(testCode)
+	require.NoError(t, err)
+	m, err := Decode(code)
+	require.NoError(t, err)
+	in, err := NewInstance(m, testResolver{"env.host": host}, Config{MaxMemoryPages: 2, MaxCallStack: 64, GasPerInstruction: 1})
+	require.NoError(t, err)
+	return in
+}
+
+func TestCall(t *testing.T) {
+	var cases = []struct {
+		name     string
+		params   []int64
+		expected int64
+		err      error
+	}{
+		{"sum", []int64{10}, 55, nil},
+		{"fib", []int64{15}, 610, nil},
+		{"classify", []int64{0}, 10, nil},
+		{"classify", []int64{1}, 20, nil},
+		{"classify", []int64{2}, 30, nil},
+		{"classify", []int64{-1}, 30, nil},
+		{"ifelse", []int64{1}, 101, nil},
+		{"ifelse", []int64{0}, 102, nil},
+		{"indirect", []int64{0}, 6, nil},
+		{"indirect", []int64{1}, 101, nil},
+		{"indirect", []int64{2}, 0, ErrSignatureMismatch},
+		{"indirect", []int64{3}, 0, ErrUndefinedElement},
+		{"indirect", []int64{4}, 0, ErrUndefinedElement},
+		{"grow", []int64{1}, 1, nil},
+		{"grow", []int64{1}, -1, nil},
+		{"store", []int64{0x180}, -128, nil},
+		{"load", []int64{2*PageSize - 4}, 0, nil},
+		{"load", []int64{2*PageSize - 3}, 0, ErrOutOfBoundsMemory},
+		{"div", []int64{2}, -1073741824, nil},
+		{"div", []int64{0}, 0, ErrDivideByZero},
+		{"div", []int64{-1}, 0, ErrIntegerOverflow},
+		{"callhost", []int64{3}, 8, nil},
+		{"counter", nil, 1, nil},
+		{"counter", nil, 2, nil},
+		{"mul64", []int64{1 << 32, 3 << 8}, 3 << 40, nil},
+		{"trap", nil, 0, ErrUnreachable},
+		{"none", nil, 0, ErrExportNotFound},
+		{"sum", nil, 0, ErrInvalidArgumentsCount},
+	}
+
+	in := newTestInstance(t, func(mem []byte, args []int64) int64 {
+		return args[0] + args[1]
+	})
+	for i, cs := range cases {
+		ret, err := in.Call(sdk.NewInfiniteGasMeter(), cs.name, cs.params...)
+		if cs.err != nil {
+			assert.Equal(t, cs.err, err, "case %v", i)
+		} else if assert.NoError(t, err, "case %v", i) {
+			assert.Equal(t, cs.expected, ret, "case %v", i)
+		}
+	}
+}
+
+func TestCallStack(t *testing.T) {
+	in := newTestInstance(t, nil)
+	_, err := in.Call(sdk.NewInfiniteGasMeter(), "trap")
+	assert.Equal(t, ErrUnreachable, err)
+	assert.Equal(t, []uint32{13}, in.CallStack())
+
+	_, err = in.Call(sdk.NewInfiniteGasMeter(), "fib", 100)
+	assert.Equal(t, ErrCallStackExceeded, err)
+	assert.Len(t, in.CallStack(), 64)
+}
+
+func TestGas(t *testing.T) {
+	in := newTestInstance(t, func(mem []byte, args []int64) int64 {
+		return 0
+	})
+	meter := sdk.NewInfiniteGasMeter()
+	_, err := in.Call(meter, "sum", 10)
+	require.NoError(t, err)
+	used := meter.GasConsumed()
+	assert.NotZero(t, used)
+
+	// the gas increases with the number of the instructions executed
+	meter = sdk.NewInfiniteGasMeter()
+	_, err = in.Call(meter, "sum", 20)
+	require.NoError(t, err)
+	assert.True(t, meter.GasConsumed() > used)
+
+	meter = sdk.NewGasMeter(used - 1)
+	assert.PanicsWithValue(t, sdk.ErrorOutOfGas{Descriptor: gasDescInstruction}, func() {
+		in.Call(meter, "sum", 10)
+	})
+	assert.True(t, meter.GasConsumed() > meter.Limit())
+
+	// the gas consumed by host functions is charged to the same meter
+	in = newTestInstance(t, func(mem []byte, args []int64) int64 {
+		meter.ConsumeGas(100, "host")
+		return 0
+	})
+	meter = sdk.NewInfiniteGasMeter()
+	_, err = in.Call(meter, "callhost", 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100+4), meter.GasConsumed())
+}
+
+func TestHostPanic(t *testing.T) {
+	in := newTestInstance(t, func(mem []byte, args []int64) int64 {
+		panic("host")
+	})
+	_, err := in.Call(sdk.NewInfiniteGasMeter(), "callhost", 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "host")
+}
+
+func TestDecode(t *testing.T) {
+	var cases = []struct {
+		code  string
+		valid bool
+	}{
+		{testCode, true},
+		// add(f32, f32) f32
+		{"0061736d0100000001070160027d7d017d030201000a0901070020002001920b", false},
+		// a function whose block doesn't end
+		{"0061736d01000000010401600000030201000a0601040002400b", false},
+		// a branch to an undefined label
+		{"0061736d01000000010401600000030201000a060104000c010b", false},
+	}
+	for i, cs := range cases {
+		code, err := hex.DecodeString(cs.code)
+		require.NoError(t, err, "case %v", i)
+		_, err = Decode(code)
+		assert.Equal(t, cs.valid, err == nil, "case %v: %v", i, err)
+	}
+}
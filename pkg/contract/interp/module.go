@@ -0,0 +1,321 @@
+// Package interp is a wasm interpreter which executes contracts.
+// It supports the integer instructions of the wasm MVP, and charges gas for every instruction it executes.
+package interp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/go-interpreter/wagon/wasm"
+	"github.com/go-interpreter/wagon/wasm/leb128"
+)
+
+const (
+	// PageSize is the size of a memory page
+	PageSize = 65536
+	// MaxTableSize is the max number of elements of a table
+	MaxTableSize = 65536
+	// MaxLocals is the max number of locals of a function including the params
+	MaxLocals = 65536
+)
+
+// noElem is an uninitialized element of a table
+const noElem = ^uint32(0)
+
+var errFloat = errors.New("floating-point is not supported")
+
+type funcType struct {
+	params  []wasm.ValueType
+	results []wasm.ValueType
+}
+
+func (t funcType) equal(o funcType) bool {
+	return equalValueTypes(t.params, o.params) && equalValueTypes(t.results, o.results)
+}
+
+func equalValueTypes(a, b []wasm.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// instr is a decoded instruction.
+// a is the index, the depth, the offset or the end of a block, b is the else of an if, and imm is a constant.
+type instr struct {
+	op  byte
+	a   uint32
+	b   uint32
+	imm uint64
+}
+
+type function struct {
+	typ       funcType
+	numLocals int
+	code      []instr
+	// tables are the targets of br_table, whose last element is the default target
+	tables [][]uint32
+}
+
+type importFunc struct {
+	module, field string
+	typ           funcType
+}
+
+type global struct {
+	mutable bool
+	value   uint64
+}
+
+type dataSegment struct {
+	offset uint32
+	data   []byte
+}
+
+// Module is a decoded wasm module, which is shared by the instances of the module
+type Module struct {
+	types     []funcType
+	imports   []importFunc
+	funcs     []*function
+	globals   []global
+	table     []uint32
+	memPages  uint32
+	maxPages  uint32
+	hasMemory bool
+	data      []dataSegment
+	exports   map[string]uint32
+}
+
+// Decode decodes the wasm module. The start function of the module is ignored.
+func Decode(code []byte) (m *Module, err error) {
+	// the wasm parser may panic on malformed input
+	defer func() {
+		if r := recover(); r != nil {
+			m, err = nil, fmt.Errorf("invalid module: %v", r)
+		}
+	}()
+	base, err := wasm.DecodeModule(bytes.NewReader(code))
+	if err != nil {
+		return nil, err
+	}
+	m = &Module{exports: make(map[string]uint32)}
+	if base.Types != nil {
+		for _, s := range base.Types.Entries {
+			if hasFloat(s.ParamTypes...) || hasFloat(s.ReturnTypes...) {
+				return nil, errFloat
+			}
+			if len(s.ReturnTypes) > 1 {
+				return nil, errors.New("multiple return values are not supported")
+			}
+			m.types = append(m.types, funcType{params: s.ParamTypes, results: s.ReturnTypes})
+		}
+	}
+	if base.Import != nil {
+		for _, imp := range base.Import.Entries {
+			fi, ok := imp.Type.(wasm.FuncImport)
+			if !ok {
+				return nil, fmt.Errorf("unsupported import kind: %v.%v", imp.ModuleName, imp.FieldName)
+			}
+			typ, err := m.funcType(fi.Type)
+			if err != nil {
+				return nil, err
+			}
+			m.imports = append(m.imports, importFunc{module: imp.ModuleName, field: imp.FieldName, typ: typ})
+		}
+	}
+	if err := m.decodeGlobals(base); err != nil {
+		return nil, err
+	}
+	if err := m.decodeTable(base); err != nil {
+		return nil, err
+	}
+	if err := m.decodeMemory(base); err != nil {
+		return nil, err
+	}
+	if base.Function != nil {
+		if base.Code == nil || len(base.Code.Bodies) != len(base.Function.Types) {
+			return nil, errors.New("the number of functions and bodies are unequal")
+		}
+		for _, ti := range base.Function.Types {
+			typ, err := m.funcType(ti)
+			if err != nil {
+				return nil, err
+			}
+			m.funcs = append(m.funcs, &function{typ: typ})
+		}
+		for i, body := range base.Code.Bodies {
+			if err := m.decodeFunction(m.funcs[i], body); err != nil {
+				return nil, fmt.Errorf("function %v: %v", len(m.imports)+i, err)
+			}
+		}
+	}
+	if err := m.decodeElements(base); err != nil {
+		return nil, err
+	}
+	if base.Export != nil {
+		for name, e := range base.Export.Entries {
+			if e.Kind != wasm.ExternalFunction {
+				continue
+			}
+			if int(e.Index) >= m.numFuncs() {
+				return nil, fmt.Errorf("invalid export: %v", name)
+			}
+			m.exports[name] = e.Index
+		}
+	}
+	return m, nil
+}
+
+func (m *Module) numFuncs() int {
+	return len(m.imports) + len(m.funcs)
+}
+
+func (m *Module) funcType(idx uint32) (funcType, error) {
+	if int(idx) >= len(m.types) {
+		return funcType{}, fmt.Errorf("invalid type index: %v", idx)
+	}
+	return m.types[idx], nil
+}
+
+// typeOf returns the type of the function in the function index space
+func (m *Module) typeOf(idx uint32) funcType {
+	if int(idx) < len(m.imports) {
+		return m.imports[idx].typ
+	}
+	return m.funcs[int(idx)-len(m.imports)].typ
+}
+
+func (m *Module) decodeGlobals(base *wasm.Module) error {
+	if base.Global == nil {
+		return nil
+	}
+	for _, g := range base.Global.Globals {
+		if hasFloat(g.Type.Type) {
+			return errFloat
+		}
+		v, err := constExpr(g.Init)
+		if err != nil {
+			return err
+		}
+		if g.Type.Type == wasm.ValueTypeI32 {
+			v = uint64(uint32(v))
+		}
+		m.globals = append(m.globals, global{mutable: g.Type.Mutable, value: v})
+	}
+	return nil
+}
+
+func (m *Module) decodeTable(base *wasm.Module) error {
+	if base.Table == nil || len(base.Table.Entries) == 0 {
+		return nil
+	}
+	if len(base.Table.Entries) > 1 {
+		return errors.New("multiple tables are not supported")
+	}
+	size := base.Table.Entries[0].Limits.Initial
+	if size > MaxTableSize {
+		return fmt.Errorf("table size %v exceeds the limit %v", size, MaxTableSize)
+	}
+	m.table = make([]uint32, size)
+	for i := range m.table {
+		m.table[i] = noElem
+	}
+	return nil
+}
+
+func (m *Module) decodeElements(base *wasm.Module) error {
+	if base.Elements == nil {
+		return nil
+	}
+	for _, e := range base.Elements.Entries {
+		v, err := constExpr(e.Offset)
+		if err != nil {
+			return err
+		}
+		offset := uint64(uint32(v))
+		if e.Index != 0 || offset+uint64(len(e.Elems)) > uint64(len(m.table)) {
+			return errors.New("element segment is out of the table")
+		}
+		for i, idx := range e.Elems {
+			if int(idx) >= m.numFuncs() {
+				return fmt.Errorf("invalid element: %v", idx)
+			}
+			m.table[offset+uint64(i)] = idx
+		}
+	}
+	return nil
+}
+
+func (m *Module) decodeMemory(base *wasm.Module) error {
+	if base.Memory != nil && len(base.Memory.Entries) > 0 {
+		if len(base.Memory.Entries) > 1 {
+			return errors.New("multiple memories are not supported")
+		}
+		limits := base.Memory.Entries[0].Limits
+		m.hasMemory = true
+		m.memPages = limits.Initial
+		m.maxPages = ^uint32(0)
+		if limits.Flags&1 != 0 {
+			m.maxPages = limits.Maximum
+		}
+	}
+	if base.Data == nil {
+		return nil
+	}
+	for _, d := range base.Data.Entries {
+		if d.Index != 0 || !m.hasMemory {
+			return errors.New("data segment has no memory")
+		}
+		v, err := constExpr(d.Offset)
+		if err != nil {
+			return err
+		}
+		m.data = append(m.data, dataSegment{offset: uint32(v), data: d.Data})
+	}
+	return nil
+}
+
+// constExpr evaluates an initializer expression which is a constant
+func constExpr(expr []byte) (uint64, error) {
+	r := bytes.NewReader(expr)
+	op, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	switch op {
+	case opI32Const:
+		i, err := leb128.ReadVarint32(r)
+		if err != nil {
+			return 0, err
+		}
+		v = uint64(uint32(i))
+	case opI64Const:
+		i, err := leb128.ReadVarint64(r)
+		if err != nil {
+			return 0, err
+		}
+		v = uint64(i)
+	default:
+		return 0, fmt.Errorf("unsupported initializer expression: %#x", op)
+	}
+	if end, err := r.ReadByte(); err != nil || end != opEnd || r.Len() != 0 {
+		return 0, errors.New("invalid initializer expression")
+	}
+	return v, nil
+}
+
+func hasFloat(ts ...wasm.ValueType) bool {
+	for _, t := range ts {
+		if t == wasm.ValueTypeF32 || t == wasm.ValueTypeF64 {
+			return true
+		}
+	}
+	return false
+}
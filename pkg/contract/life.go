@@ -0,0 +1,155 @@
+package contract
+
+import (
+	"fmt"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/go-interpreter/wagon/wasm"
+	"github.com/perlin-network/life/exec"
+)
+
+// LifeEngine runs contracts on the interpreter of life
+type LifeEngine struct {
+	cache *ModuleCache
+}
+
+var _ Engine = (*LifeEngine)(nil)
+
+// NewLifeEngine returns an engine which reuses the compiled modules in the cache.
+// If the cache is nil, the code is compiled on every execution.
+func NewLifeEngine(cache *ModuleCache) *LifeEngine {
+	return &LifeEngine{cache: cache}
+}
+
+func (e *LifeEngine) Name() string {
+	return EngineLife
+}
+
+func (e *LifeEngine) Instantiate(code []byte, hfs HostFunctions, params VMParams) (Instance, error) {
+	if e.cache == nil {
+		v, err := exec.NewVirtualMachine(code, newVMConfig(params), &lifeResolver{hfs: hfs}, DefaultGasPolicy())
+		if err != nil {
+			return nil, err
+		}
+		return &VM{VirtualMachine: v}, nil
+	}
+	h := CodeHash(code)
+	tmpl, ok := e.cache.get(h)
+	if !ok {
+		// the template is shared by the executions with any params
+		v, err := exec.NewVirtualMachine(code, newVMConfig(DefaultVMParams()), templateResolver{}, DefaultGasPolicy())
+		if err != nil {
+			return nil, err
+		}
+		tmpl = v
+		e.cache.add(h, tmpl)
+	}
+	return instantiate(tmpl, &lifeResolver{hfs: hfs}, newVMConfig(params))
+}
+
+func newVMConfig(params VMParams) exec.VMConfig {
+	return exec.VMConfig{
+		EnableJIT:                false,
+		DefaultMemoryPages:       128,
+		MaxMemoryPages:           int(params.MaxMemoryPages),
+		MaxCallStackDepth:        int(params.MaxCallStack),
+		DefaultTableSize:         65536,
+		ReturnOnGasLimitExceeded: true,
+	}
+}
+
+// instantiate creates a vm which shares the compiled module with the template.
+// The vm has the copies of the initial memory, globals and table of the template.
+func instantiate(tmpl *exec.VirtualMachine, r exec.ImportResolver, config exec.VMConfig) (vm *VM, err error) {
+	if len(tmpl.Memory) > config.MaxMemoryPages*exec.DefaultPageSize {
+		return nil, fmt.Errorf("max memory exceeded")
+	}
+	// the resolver panics on unknown imports like exec.NewVirtualMachine
+	defer func() {
+		if rc := recover(); rc != nil {
+			vm, err = nil, fmt.Errorf("%v", rc)
+		}
+	}()
+	var imports []exec.FunctionImport
+	if m := tmpl.Module.Base; m.Import != nil {
+		for _, imp := range m.Import.Entries {
+			if imp.Type.Kind() == wasm.ExternalFunction {
+				imports = append(imports, r.ResolveFunc(imp.ModuleName, imp.FieldName))
+			}
+		}
+	}
+	return &VM{VirtualMachine: &exec.VirtualMachine{
+		Module:          tmpl.Module,
+		Config:          config,
+		FunctionCode:    tmpl.FunctionCode,
+		FunctionImports: imports,
+		CallStack:       make([]exec.Frame, exec.DefaultCallStackSize),
+		CurrentFrame:    -1,
+		Table:           append([]uint32(nil), tmpl.Table...),
+		Globals:         append([]int64(nil), tmpl.Globals...),
+		Memory:          append([]byte(nil), tmpl.Memory...),
+		Exited:          true,
+	}}, nil
+}
+
+// VM is an instance of life
+type VM struct {
+	*exec.VirtualMachine
+}
+
+var _ Instance = (*VM)(nil)
+
+func (vm *VM) Call(meter sdk.GasMeter, entry string, params ...int64) (int64, error) {
+	id, ok := vm.GetFunctionExport(entry)
+	if !ok {
+		return 0, ErrEntryNotFound
+	}
+	return vm.RunWithGasMeter(meter, id, params...)
+}
+
+func (vm *VM) Memory() []byte {
+	return vm.VirtualMachine.Memory
+}
+
+// StackTrace returns the call stack. The names of functions come from the name section of the module.
+func (vm *VM) StackTrace() []string {
+	var trace []string
+	names := functionNames(vm.Module.Base)
+	for i := vm.CurrentFrame; i >= 0; i-- {
+		id := vm.CallStack[i].FunctionID
+		trace = append(trace, fmt.Sprintf("<%d> [%d] %s", i, id, names[id]))
+	}
+	return trace
+}
+
+// lifeResolver resolves the imports of life with the host functions
+type lifeResolver struct {
+	hfs HostFunctions
+}
+
+func (r *lifeResolver) ResolveFunc(module, field string) exec.FunctionImport {
+	f, err := r.hfs.Resolve(module, field)
+	if err != nil {
+		panic(err)
+	}
+	return func(vm *exec.VirtualMachine) int64 {
+		// the locals of an import function are the arguments of the call
+		return f(vm.Memory, vm.GetCurrentFrame().Locals)
+	}
+}
+
+func (r *lifeResolver) ResolveGlobal(module, field string) int64 {
+	panic(fmt.Errorf("not supported module: %s %s", module, field))
+}
+
+// templateResolver resolves the imports of a template vm.
+// The functions are resolved again with the resolver of each execution.
+type templateResolver struct{}
+
+func (templateResolver) ResolveFunc(module, field string) exec.FunctionImport {
+	return nil
+}
+
+func (templateResolver) ResolveGlobal(module, field string) int64 {
+	panic(fmt.Errorf("not supported module: %s %s", module, field))
+}
@@ -27,17 +27,48 @@ func (cm *ContractManager) SaveContract(ctx types.Context, addr common.Address,
 	return nil
 }
 
-func (cm *ContractManager) DeployContract(ctx types.Context, tx *transaction.ContractDeployTx) (common.Address, error) {
-	c := TxToContract(tx)
-	addr := c.Address()
-	_, err := cm.GetContract(ctx, addr)
-	if err == nil {
+// UploadCode stores the code into the code store, and returns the hash of it.
+// Uploading the code which already exists is a no-op.
+func (cm *ContractManager) UploadCode(ctx types.Context, code []byte) (common.Hash, error) {
+	h := CodeHash(code)
+	if cm.mapper.HasCode(ctx, h) {
+		return h, nil
+	}
+	if err := ValidateCode(code); err != nil {
+		return h, err
+	}
+	return cm.mapper.PutCode(ctx, code), nil
+}
+
+// InstantiateContract creates a new contract instance of the uploaded code.
+// The address of the instance is derived from the sender and the nonce of tx.
+func (cm *ContractManager) InstantiateContract(ctx types.Context, tx *transaction.ContractInstantiateTx) (common.Address, error) {
+	code, err := cm.mapper.GetCode(ctx, tx.CodeHash)
+	if err != nil {
+		return common.Address{}, err
+	}
+	addr := NewContractAddress(tx.Common.From, tx.Common.Nonce)
+	if cm.mapper.Has(ctx, addr) {
 		return addr, fmt.Errorf("already exists: %v", addr.Hex())
-	} else if err != ErrContractNotFound {
-		return addr, err
 	}
-	if err := cm.SaveContract(ctx, addr, c); err != nil {
+	if err := cm.SaveContract(ctx, addr, NewContract(tx.Common.From, addr, code)); err != nil {
 		return addr, err
 	}
 	return addr, nil
 }
+
+// UpgradeContract replaces the code of the contract. Only the owner can upgrade it.
+func (cm *ContractManager) UpgradeContract(ctx types.Context, tx *transaction.ContractUpgradeTx) error {
+	c, err := cm.GetContract(ctx, tx.Address)
+	if err != nil {
+		return err
+	}
+	if c.Owner != tx.Common.From {
+		return fmt.Errorf("sender is not the owner: owner=%v sender=%v", c.Owner.Hex(), tx.Common.From.Hex())
+	}
+	if _, err := cm.UploadCode(ctx, tx.Code); err != nil {
+		return err
+	}
+	c.Code = tx.Code
+	return cm.SaveContract(ctx, tx.Address, c)
+}
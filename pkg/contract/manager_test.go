@@ -0,0 +1,129 @@
+package contract
+
+import (
+	"encoding/hex"
+	"testing"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/testutil"
+	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// modules which export init that returns 0 and 1 respectively
+const (
+	testCode0 = "0061736d01000000010b0260027f7f017f6000017f02160103656e760e5f5f7365745f726573706f6e7365000003020101050301000107080104696e697400010a0601040041000b"
+	testCode1 = "0061736d01000000010b0260027f7f017f6000017f02160103656e760e5f5f7365745f726573706f6e7365000003020101050301000107080104696e697400010a0601040041010b"
+	// init returns -2
+	testCodeFail = "0061736d01000000010b0260027f7f017f6000017f02160103656e760e5f5f7365745f726573706f6e7365000003020101050301000107080104696e697400010a06010400417e0b"
+	// init reverts with code 5
+	testCodeRevert = "0061736d01000000010c0260037f7f7f017f6000017f02100103656e76085f5f726576657274000003020101050301000107080104696e697400010a0f010d0041054100410010001a41000b"
+)
+
+func newTestContractManager(t *testing.T) (sdk.Context, *ContractManager) {
+	key := sdk.NewKVStoreKey("contract")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	require.NoError(t, err)
+	return sdk.NewContext(cms, abci.Header{}, false, nil), NewContractManager(NewContractMapper(key))
+}
+
+func TestInstantiateContract(t *testing.T) {
+	require := require.New(t)
+	ctx, cm := newTestContractManager(t)
+
+	deployer := common.BytesToAddress([]byte("deployer"))
+	code, _ := hex.DecodeString(testCode0)
+
+	// invalid code cannot be uploaded
+	_, err := cm.UploadCode(ctx, []byte("code"))
+	require.Error(err)
+
+	h, err := cm.UploadCode(ctx, code)
+	require.NoError(err)
+	require.Equal(CodeHash(code), h)
+	// uploading same code is a no-op
+	h2, err := cm.UploadCode(ctx, code)
+	require.NoError(err)
+	require.Equal(h, h2)
+
+	var instantiate = func(hash common.Hash, nonce uint64) (common.Address, error) {
+		return cm.InstantiateContract(ctx, &transaction.ContractInstantiateTx{
+			Common:   transaction.CommonTx{From: deployer, Nonce: nonce},
+			CodeHash: hash,
+		})
+	}
+
+	// same code can be instantiated many times
+	addr0, err := instantiate(h, 0)
+	require.NoError(err)
+	addr1, err := instantiate(h, 1)
+	require.NoError(err)
+	require.NotEqual(addr0, addr1)
+	require.Equal(NewContractAddress(deployer, 1), addr1)
+
+	for _, addr := range []common.Address{addr0, addr1} {
+		c, err := cm.GetContract(ctx, addr)
+		require.NoError(err)
+		require.Equal(code, c.Code)
+		require.Equal(deployer, c.Owner)
+		require.Equal(addr, c.Address())
+	}
+
+	// the instance already exists
+	_, err = instantiate(h, 1)
+	require.Error(err)
+	// the code is not uploaded
+	_, err = instantiate(CodeHash([]byte("code")), 2)
+	require.Error(err)
+
+	// saving an instance doesn't store the code
+	addr2 := NewContractAddress(deployer, 2)
+	require.NoError(cm.SaveContract(ctx, addr2, NewContract(deployer, addr2, []byte("code"))))
+	_, err = cm.GetContract(ctx, addr2)
+	require.Equal(ErrCodeNotFound, err)
+}
+
+func TestUpgradeContract(t *testing.T) {
+	require := require.New(t)
+	ctx, cm := newTestContractManager(t)
+
+	owner := common.BytesToAddress([]byte("owner"))
+	code0, _ := hex.DecodeString(testCode0)
+	code1, _ := hex.DecodeString(testCode1)
+
+	h, err := cm.UploadCode(ctx, code0)
+	require.NoError(err)
+	addr, err := cm.InstantiateContract(ctx, &transaction.ContractInstantiateTx{
+		Common:   transaction.CommonTx{From: owner},
+		CodeHash: h,
+	})
+	require.NoError(err)
+
+	var upgrade = func(from common.Address, addr common.Address, code []byte) error {
+		return cm.UpgradeContract(ctx, &transaction.ContractUpgradeTx{
+			Common:  transaction.CommonTx{From: from},
+			Address: addr,
+			Code:    code,
+		})
+	}
+
+	// only the owner can upgrade the contract
+	require.Error(upgrade(common.BytesToAddress([]byte("other")), addr, code1))
+	// the contract must exist
+	require.Error(upgrade(owner, common.BytesToAddress([]byte("unknown")), code1))
+	// the new code must be valid
+	require.Error(upgrade(owner, addr, []byte("code")))
+
+	require.NoError(upgrade(owner, addr, code1))
+	c, err := cm.GetContract(ctx, addr)
+	require.NoError(err)
+	require.Equal(code1, c.Code)
+	require.Equal(owner, c.Owner)
+	// the address is kept after the upgrade
+	require.Equal(addr, c.Address())
+	// the new code is uploaded and the old one is kept
+	require.True(cm.mapper.HasCode(ctx, CodeHash(code1)))
+	require.True(cm.mapper.HasCode(ctx, h))
+}
@@ -3,11 +3,38 @@ package contract
 import (
 	"github.com/bluele/hypermint/pkg/abci/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
+var (
+	codePrefix     = []byte("code/")
+	instancePrefix = []byte("contract/")
+)
+
+// CodeMapper is a store of contract codes keyed by the code hash
+type CodeMapper interface {
+	PutCode(ctx types.Context, code []byte) common.Hash
+	GetCode(ctx types.Context, hash common.Hash) ([]byte, error)
+	HasCode(ctx types.Context, hash common.Hash) bool
+	// IterateCodes calls cb with every code in the order of the hash until cb returns true
+	IterateCodes(ctx types.Context, cb func(hash common.Hash, code []byte) (stop bool))
+}
+
+// ContractMapper is a store of contract instances keyed by the contract address
 type ContractMapper interface {
+	CodeMapper
 	Put(ctx types.Context, addr common.Address, c *Contract)
 	Get(ctx types.Context, addr common.Address) (*Contract, error)
+	Has(ctx types.Context, addr common.Address) bool
+	// IterateContracts calls cb with the owner and the code hash of every contract
+	// in the order of the address until cb returns true
+	IterateContracts(ctx types.Context, cb func(addr, owner common.Address, codeHash common.Hash) (stop bool))
+}
+
+// contractInstance is an entry of the instance store
+type contractInstance struct {
+	Owner    common.Address
+	CodeHash common.Hash
 }
 
 type contractMapper struct {
@@ -20,34 +47,85 @@ func NewContractMapper(storeKey types.StoreKey) ContractMapper {
 	}
 }
 
-func (cm *contractMapper) Put(ctx types.Context, addr common.Address, c *Contract) {
-	cm.put(cm.getStore(ctx), addr, c)
+// PutCode stores the code if it doesn't exist yet, and returns the hash of it
+func (cm *contractMapper) PutCode(ctx types.Context, code []byte) common.Hash {
+	h := CodeHash(code)
+	if !cm.HasCode(ctx, h) {
+		cm.getCodeStore(ctx).Set(h.Bytes(), code)
+	}
+	return h
 }
 
-func (cm *contractMapper) put(kvs types.KVStore, addr common.Address, c *Contract) {
-	b, err := c.Encode()
+func (cm *contractMapper) GetCode(ctx types.Context, hash common.Hash) ([]byte, error) {
+	code := cm.getCodeStore(ctx).Get(hash.Bytes())
+	if code == nil {
+		return nil, ErrCodeNotFound
+	}
+	return code, nil
+}
+
+func (cm *contractMapper) HasCode(ctx types.Context, hash common.Hash) bool {
+	return cm.getCodeStore(ctx).Has(hash.Bytes())
+}
+
+func (cm *contractMapper) IterateCodes(ctx types.Context, cb func(hash common.Hash, code []byte) (stop bool)) {
+	it := cm.getCodeStore(ctx).Iterator(nil, nil)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		if cb(common.BytesToHash(it.Key()), it.Value()) {
+			return
+		}
+	}
+}
+
+// Put stores the owner and the code hash of the contract instance.
+// The code must be stored by PutCode.
+func (cm *contractMapper) Put(ctx types.Context, addr common.Address, c *Contract) {
+	b, err := rlp.EncodeToBytes(contractInstance{Owner: c.Owner, CodeHash: CodeHash(c.Code)})
 	if err != nil {
 		panic(err)
 	}
-	kvs.Set(addr.Bytes(), b)
+	cm.getInstanceStore(ctx).Set(addr.Bytes(), b)
 }
 
 func (cm *contractMapper) Get(ctx types.Context, addr common.Address) (*Contract, error) {
-	return cm.get(cm.getStore(ctx), addr)
-}
-
-func (cm *contractMapper) get(kvs types.KVStore, addr common.Address) (*Contract, error) {
-	v := kvs.Get(addr.Bytes())
+	v := cm.getInstanceStore(ctx).Get(addr.Bytes())
 	if v == nil {
 		return nil, ErrContractNotFound
 	}
-	c := new(Contract)
-	if err := c.Decode(v); err != nil {
+	var ci contractInstance
+	if err := rlp.DecodeBytes(v, &ci); err != nil {
+		return nil, err
+	}
+	code, err := cm.GetCode(ctx, ci.CodeHash)
+	if err != nil {
 		return nil, err
 	}
-	return c, nil
+	return NewContract(ci.Owner, addr, code), nil
+}
+
+func (cm *contractMapper) Has(ctx types.Context, addr common.Address) bool {
+	return cm.getInstanceStore(ctx).Has(addr.Bytes())
+}
+
+func (cm *contractMapper) IterateContracts(ctx types.Context, cb func(addr, owner common.Address, codeHash common.Hash) (stop bool)) {
+	it := cm.getInstanceStore(ctx).Iterator(nil, nil)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		var ci contractInstance
+		if err := rlp.DecodeBytes(it.Value(), &ci); err != nil {
+			panic(err)
+		}
+		if cb(common.BytesToAddress(it.Key()), ci.Owner, ci.CodeHash) {
+			return
+		}
+	}
+}
+
+func (cm *contractMapper) getCodeStore(ctx types.Context) types.KVStore {
+	return ctx.KVStore(cm.storeKey).Prefix(codePrefix)
 }
 
-func (cm *contractMapper) getStore(ctx types.Context) types.KVStore {
-	return ctx.KVStore(cm.storeKey)
+func (cm *contractMapper) getInstanceStore(ctx types.Context) types.KVStore {
+	return ctx.KVStore(cm.storeKey).Prefix(instancePrefix)
 }
@@ -0,0 +1,105 @@
+package contract
+
+import (
+	"fmt"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/perlin-network/life/exec"
+)
+
+var vmParamsKey = []byte("vm_params")
+
+// VMParams are the resource limits of contract execution, which are shared by the chain.
+// They are configured in the genesis.
+type VMParams struct {
+	// MaxMemoryPages is the max number of memory pages of a contract
+	MaxMemoryPages uint32 `json:"max_memory_pages"`
+	// MaxCallStack is the max depth of the call stack in a contract
+	MaxCallStack uint32 `json:"max_call_stack"`
+	// MaxArgsSize is the max total size of the arguments of a call
+	MaxArgsSize uint32 `json:"max_args_size"`
+	// MaxResponseSize is the max size of the response of a call
+	MaxResponseSize uint32 `json:"max_response_size"`
+	// MaxValueSize is the max size of a value which a contract writes to the state
+	MaxValueSize uint32 `json:"max_value_size"`
+	// MaxEvents is the max number of events which contracts emit in a transaction
+	MaxEvents uint32 `json:"max_events"`
+	// MaxCallDepth is the max depth of nested contract calls. The top-level call is 0.
+	MaxCallDepth uint32 `json:"max_call_depth"`
+}
+
+// DefaultVMParams returns the params which are used if the genesis doesn't configure them
+func DefaultVMParams() VMParams {
+	return VMParams{
+		MaxMemoryPages:  MaxMemoryPages,
+		MaxCallStack:    exec.DefaultCallStackSize,
+		MaxArgsSize:     1024 * 1024,
+		MaxResponseSize: 1024 * 1024,
+		MaxValueSize:    64 * 1024,
+		MaxEvents:       256,
+		MaxCallDepth:    16,
+	}
+}
+
+// Validate returns an error if any param is out of range
+func (p VMParams) Validate() error {
+	if p.MaxMemoryPages == 0 || p.MaxMemoryPages > MaxMemoryPages {
+		return fmt.Errorf("max_memory_pages must be in [1, %v]", MaxMemoryPages)
+	}
+	if p.MaxCallStack == 0 || p.MaxCallStack > exec.DefaultCallStackSize {
+		return fmt.Errorf("max_call_stack must be in [1, %v]", exec.DefaultCallStackSize)
+	}
+	if p.MaxArgsSize == 0 || p.MaxResponseSize == 0 || p.MaxValueSize == 0 || p.MaxEvents == 0 {
+		return fmt.Errorf("max_args_size, max_response_size, max_value_size and max_events must be positive")
+	}
+	if p.MaxCallDepth == 0 {
+		return fmt.Errorf("max_call_depth must be positive")
+	}
+	return nil
+}
+
+// SetParams stores the params in the chain state
+func (em *EnvManager) SetParams(ctx sdk.Context, p VMParams) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	b, err := rlp.EncodeToBytes(p)
+	if err != nil {
+		return err
+	}
+	ctx.KVStore(em.key).Set(vmParamsKey, b)
+	return nil
+}
+
+// GetParams returns the params in the chain state, or DefaultVMParams if they are not stored.
+func (em *EnvManager) GetParams(ctx sdk.Context) VMParams {
+	// the contract doesn't pay gas for reading the params
+	b := ctx.MultiStore().GetKVStore(em.key).Get(vmParamsKey)
+	if b == nil {
+		return DefaultVMParams()
+	}
+	var p VMParams
+	if err := rlp.DecodeBytes(b, &p); err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// LimitError aborts the whole transaction which exceeds a limit of VMParams
+type LimitError struct {
+	Name  string
+	Value int
+	Max   uint32
+}
+
+func (e LimitError) Error() string {
+	return fmt.Sprintf("%v %v exceeds the limit %v", e.Name, e.Value, e.Max)
+}
+
+// checkLimit panics with *LimitError if the value exceeds the max
+func checkLimit(name string, value int, max uint32) {
+	if value > int(max) {
+		panic(&LimitError{Name: name, Value: value, Max: max})
+	}
+}
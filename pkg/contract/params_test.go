@@ -0,0 +1,97 @@
+package contract
+
+import (
+	"encoding/hex"
+	"testing"
+
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/db"
+	"github.com/bluele/hypermint/pkg/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// testCodeLimits is a wasm module which has 2 memory pages and exports following functions:
+// write: writes "value" to "key"
+// resp: sets "keyvalue" to the response
+// emit: emits 2 events
+// deep: calls itself recursively 10 times
+const testCodeLimits = "0061736d0100000001130360047f7f7f7f017f60027f7f017f6000017f023d0303656e760d5f5f77726974655f7374617465000003656e760e5f5f7365745f726573706f6e7365000103656e760c5f5f656d69745f6576656e7400000305040202020205030100020606017f0141000b071e0405777269746500030472657370000404656d69740005046465657000060a4f040f00410041034103410510001a41000b0b004100410810011a41000b1a00410041034103410510021a410041034103410510021a41000b1600230041016a24002300410a48044010061a0b41000b0b0e010041000b086b657976616c7565"
+
+func TestVMParams(t *testing.T) {
+	assert := assert.New(t)
+
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	em := NewEnvManager(key, NewContractMapper(key), account.NewAccountMapper(key))
+
+	assert.NoError(DefaultVMParams().Validate())
+	assert.Equal(DefaultVMParams(), em.GetParams(ctx))
+
+	p := DefaultVMParams()
+	p.MaxEvents = 1
+	assert.NoError(em.SetParams(ctx, p))
+	assert.Equal(p, em.GetParams(ctx))
+
+	for _, f := range []func(*VMParams){
+		func(p *VMParams) { p.MaxMemoryPages = 0 },
+		func(p *VMParams) { p.MaxMemoryPages = MaxMemoryPages + 1 },
+		func(p *VMParams) { p.MaxCallStack = 0 },
+		func(p *VMParams) { p.MaxCallStack = 513 },
+		func(p *VMParams) { p.MaxValueSize = 0 },
+		func(p *VMParams) { p.MaxCallDepth = 0 },
+	} {
+		p := DefaultVMParams()
+		f(&p)
+		assert.Error(p.Validate())
+		assert.Error(em.SetParams(ctx, p))
+	}
+}
+
+func TestVMLimits(t *testing.T) {
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(t, err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	code, _ := hex.DecodeString(testCodeLimits)
+	c := &Contract{Owner: common.BytesToAddress([]byte("owner")), Code: code}
+
+	// exec returns the error of the execution, which every configuration of the engine must agree on
+	var exec = func(params VMParams, entry string, args Args) error {
+		var errs []error
+		for _, e := range testEngines() {
+			env := &Env{
+				Contract:   c,
+				DB:         db.NewVersionedDB(ctx.KVStore(key)),
+				Args:       args,
+				Params:     params,
+				VMProvider: EngineVMProvider(e.engine),
+			}
+			_, err := env.Exec(ctx, entry)
+			errs = append(errs, err)
+		}
+		for _, err := range errs[1:] {
+			assert.IsType(t, errs[0], err)
+		}
+		return errs[0]
+	}
+	var limited = func(f func(*VMParams)) VMParams {
+		p := DefaultVMParams()
+		f(&p)
+		return p
+	}
+
+	for _, entry := range []string{"write", "resp", "emit", "deep"} {
+		assert.NoError(t, exec(DefaultVMParams(), entry, Args{}), entry)
+	}
+	assert.IsType(t, &LimitError{}, exec(limited(func(p *VMParams) { p.MaxValueSize = 4 }), "write", Args{}))
+	assert.IsType(t, &LimitError{}, exec(limited(func(p *VMParams) { p.MaxResponseSize = 7 }), "resp", Args{}))
+	assert.IsType(t, &LimitError{}, exec(limited(func(p *VMParams) { p.MaxEvents = 1 }), "emit", Args{}))
+	assert.IsType(t, &LimitError{}, exec(limited(func(p *VMParams) { p.MaxArgsSize = 4 }), "write", NewArgsFromStrings([]string{"abc", "de"})))
+	assert.Error(t, exec(limited(func(p *VMParams) { p.MaxCallStack = 5 }), "deep", Args{}))
+	assert.Error(t, exec(limited(func(p *VMParams) { p.MaxMemoryPages = 1 }), "write", Args{}))
+}
@@ -4,30 +4,55 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"time"
 
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
 	"github.com/bluele/hypermint/pkg/contract/event"
 	"github.com/bluele/hypermint/pkg/db"
 	"github.com/bluele/hypermint/pkg/logger"
+	"github.com/bluele/hypermint/pkg/transaction"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 )
 
 var defaultLogger = logger.GetDefaultLogger("*:debug").With("module", "process")
 
-var ErrArgIdxNotFound = errors.New("argument idx not found")
+var (
+	ErrArgIdxNotFound        = errors.New("argument idx not found")
+	ErrAccountMapperNotFound = errors.New("account mapper not found")
+)
 
 type Process interface {
 	Logger() logger.Logger
 	Sender() common.Address
 	ContractAddress() common.Address
+	BlockHeight() int64
+	BlockTime() time.Time
+	ChainID() string
+	Proposer() common.Address
+	TxHash() []byte
 	Args() Args
 	GetArg(idx int) ([]byte, error)
+	Value() uint64
+	GetBalance(addr common.Address) (uint64, error)
+	Transfer(to common.Address, amount uint64) error
 	State() db.StateDB
 	SetResponse([]byte)
 	Call(addr common.Address, entry []byte, args Args) (int, error)
+	StaticCall(addr common.Address, entry []byte, args Args) (int, error)
+	LockReentrancy()
+	CallError() *CallError
+	Params() VMParams
+	Log(msg string)
+	DebugInfo() *DebugInfo
+	Revert(code uint32, msg []byte)
+	CreateContract(code []byte, args Args) (common.Address, error)
+	CreateContractFromHash(hash common.Hash, args Args) (common.Address, error)
 	Read(id int) ([]byte, error)
 	ValueTable() ValueTable
-	EmitEvent(ev *event.Entry)
+	EmitEvent(ev *event.Entry) error
 }
 
 // ValueTable manages values that external contract returns.
@@ -63,6 +88,27 @@ func (p process) ContractAddress() common.Address {
 	return p.env.Contract.Address()
 }
 
+func (p process) BlockHeight() int64 {
+	return p.env.Context.BlockHeight()
+}
+
+func (p process) BlockTime() time.Time {
+	return p.env.Context.BlockHeader().Time
+}
+
+func (p process) ChainID() string {
+	return p.env.Context.ChainID()
+}
+
+func (p process) Proposer() common.Address {
+	return common.BytesToAddress(p.env.Context.BlockHeader().ProposerAddress)
+}
+
+// TxHash returns a hash of the tx which is executing
+func (p process) TxHash() []byte {
+	return tmhash.Sum(p.env.Context.TxBytes())
+}
+
 func (p process) Args() Args {
 	return p.env.Args
 }
@@ -75,21 +121,155 @@ func (p process) GetArg(idx int) ([]byte, error) {
 	return arg, nil
 }
 
+// Value returns the amount of native coin which the caller has sent to the contract
+func (p process) Value() uint64 {
+	return p.env.Value
+}
+
+func (p process) GetBalance(addr common.Address) (uint64, error) {
+	if p.env.AccountMapper == nil {
+		return 0, ErrAccountMapperNotFound
+	}
+	b, err := p.env.AccountMapper.GetBalance(p.env.Context, addr)
+	if err == account.ErrAccountNotFound {
+		return 0, nil
+	}
+	return b, err
+}
+
+// Transfer sends native coin from the contract to the address
+func (p process) Transfer(to common.Address, amount uint64) error {
+	if p.env.AccountMapper == nil {
+		return ErrAccountMapperNotFound
+	}
+	if p.env.ReadOnly {
+		return ErrReadOnly
+	}
+	// the balance of the contract must not be changed if the transfer fails halfway
+	ctx, write := p.env.Context.CacheContext()
+	if err := p.env.AccountMapper.Transfer(ctx, p.ContractAddress(), amount, to); err != nil {
+		return err
+	}
+	write()
+	return nil
+}
+
 func (p process) State() db.StateDB {
 	return p.env.DB
 }
 
 func (p *process) Call(addr common.Address, entry []byte, args Args) (int, error) {
-	env, err := p.env.EnvManager.Get(p.env.Context, p.env.Contract.Address(), addr, args)
+	return p.call(addr, entry, args, false)
+}
+
+// StaticCall calls the contract with read-only access to the state
+func (p *process) StaticCall(addr common.Address, entry []byte, args Args) (int, error) {
+	return p.call(addr, entry, args, true)
+}
+
+func (p *process) call(addr common.Address, entry []byte, args Args, readOnly bool) (int, error) {
+	res, err := p.exec(addr, entry, args, readOnly)
 	if err != nil {
+		p.env.callErr = NewCallError(res, err)
 		return -1, err
 	}
-	res, err := env.Exec(p.env.Context, string(entry))
+	p.env.callErr = nil
+	p.env.state.Update(res.State)
+	return p.ValueTable().Put(res.Response)
+}
+
+// exec executes the callee on a cache of the context, which is written only if the call succeeds
+func (p *process) exec(addr common.Address, entry []byte, args Args, readOnly bool) (*Result, error) {
+	ctx, write := p.env.Context.CacheContext()
+	if err := p.env.EnvManager.checkCall(ctx, p.env.txSender(), addr); err != nil {
+		return nil, err
+	}
+	env, err := p.env.EnvManager.Get(ctx, p.env.Contract.Address(), addr, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.setCaller(p.env, readOnly); err != nil {
+		return nil, err
+	}
+	res, err := env.Exec(ctx, string(entry))
+	if err != nil {
+		return res, err
+	}
+	write()
+	return res, nil
+}
+
+// CallError returns the error of the last call to other contract, or nil if it succeeded
+func (p *process) CallError() *CallError {
+	return p.env.callErr
+}
+
+// Log records the message into the logs of the execution
+func (p *process) Log(msg string) {
+	p.Logger().Debug(msg)
+	if p.env.logs != nil {
+		p.env.logs.add(msg)
+	}
+	if p.env.debug != nil {
+		p.env.debug.addLog(msg)
+	}
+}
+
+// DebugInfo returns the trace of execution, or nil unless debug mode is enabled
+func (p *process) DebugInfo() *DebugInfo {
+	return p.env.DebugInfo()
+}
+
+// Revert aborts the whole transaction
+func (p *process) Revert(code uint32, msg []byte) {
+	panic(&RevertError{Address: p.ContractAddress().Hex(), Code: code, Message: string(msg)})
+}
+
+func (p *process) LockReentrancy() {
+	p.env.LockReentrancy()
+}
+
+// CreateContract creates a new contract whose owner is this contract, and calls its init function.
+// If the init function fails, the contract is not created.
+func (p *process) CreateContract(code []byte, args Args) (common.Address, error) {
+	return p.createContract(func(ctx sdk.Context) (common.Address, error) {
+		return p.env.EnvManager.CreateContract(ctx, p.ContractAddress(), code)
+	}, args)
+}
+
+// CreateContractFromHash creates a new contract of the uploaded code like CreateContract
+func (p *process) CreateContractFromHash(hash common.Hash, args Args) (common.Address, error) {
+	return p.createContract(func(ctx sdk.Context) (common.Address, error) {
+		return p.env.EnvManager.CreateContractFromHash(ctx, p.ContractAddress(), hash)
+	}, args)
+}
+
+func (p *process) createContract(create func(ctx sdk.Context) (common.Address, error), args Args) (common.Address, error) {
+	if p.env.ReadOnly {
+		return common.Address{}, ErrReadOnly
+	}
+	if err := p.env.EnvManager.checkDeploy(p.env.Context, p.env.txSender()); err != nil {
+		return common.Address{}, err
+	}
+	ctx, write := p.env.Context.CacheContext()
+	addr, err := create(ctx)
+	if err != nil {
+		return addr, err
+	}
+	env, err := p.env.EnvManager.Get(ctx, p.ContractAddress(), addr, args)
 	if err != nil {
-		return int(res.Code), err
+		return addr, err
 	}
+	if err := env.setCaller(p.env, false); err != nil {
+		return addr, err
+	}
+	res, err := env.Exec(ctx, transaction.ContractInitFunc)
+	if err != nil {
+		return addr, err
+	}
+	write()
 	p.env.state.Update(res.State)
-	return p.ValueTable().Put(res.Response)
+	return addr, nil
 }
 
 func (p *process) Read(id int) ([]byte, error) {
@@ -97,9 +277,15 @@ func (p *process) Read(id int) ([]byte, error) {
 }
 
 func (p *process) SetResponse(v []byte) {
+	checkLimit("response size", len(v), p.Params().MaxResponseSize)
 	p.env.SetResponse(v)
 }
 
+// Params returns the resource limits of the execution
+func (p *process) Params() VMParams {
+	return p.env.params()
+}
+
 func (p *process) Logger() logger.Logger {
 	if p.logger != nil {
 		return p.logger
@@ -111,8 +297,17 @@ func (p process) ValueTable() ValueTable {
 	return p.vt
 }
 
-func (p *process) EmitEvent(ev *event.Entry) {
+// EmitEvent adds the event to the result. The callee of static call cannot emit any event.
+func (p *process) EmitEvent(ev *event.Entry) error {
+	if p.env.ReadOnly {
+		return ErrReadOnly
+	}
+	if p.env.usage != nil {
+		p.env.usage.events++
+		checkLimit("events", p.env.usage.events, p.Params().MaxEvents)
+	}
 	p.env.entries = append(p.env.entries, ev)
+	return nil
 }
 
 type valueT map[int][]byte
@@ -149,3 +344,21 @@ func DeserializeArgs(b []byte) (Args, error) {
 	}
 	return args, nil
 }
+
+// SerializeArgs returns bytes of args
+// bytes format is same as DeserializeArgs
+func SerializeArgs(args Args) []byte {
+	size := 4
+	for _, v := range args.values {
+		size += 4 + len(v)
+	}
+	b := make([]byte, size)
+	binary.BigEndian.PutUint32(b[0:4], uint32(args.Len()))
+	offset := 4
+	for _, v := range args.values {
+		binary.BigEndian.PutUint32(b[offset:offset+4], uint32(len(v)))
+		copy(b[offset+4:], v)
+		offset += 4 + len(v)
+	}
+	return b
+}
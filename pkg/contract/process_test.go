@@ -1,10 +1,18 @@
 package contract
 
 import (
+	"encoding/hex"
 	"fmt"
 	"testing"
 
+	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/contract/event"
+	"github.com/bluele/hypermint/pkg/permission"
+	"github.com/bluele/hypermint/pkg/testutil"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	abci "github.com/tendermint/tendermint/abci/types"
 )
 
 func TestDeserializeArgs(t *testing.T) {
@@ -41,3 +49,269 @@ func TestDeserializeArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializeArgs(t *testing.T) {
+	var cases = [][]string{
+		{},
+		{"1"},
+		{"1", "", "key"},
+	}
+
+	for i, cs := range cases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			args, err := DeserializeArgs(SerializeArgs(NewArgsFromStrings(cs)))
+			assert.NoError(t, err)
+			assert.Equal(t, len(cs), args.Len())
+			for i, s := range cs {
+				arg, ok := args.Get(i)
+				assert.True(t, ok)
+				assert.Equal(t, s, string(arg))
+			}
+		})
+	}
+}
+
+func TestNestedCall(t *testing.T) {
+	assert := assert.New(t)
+
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	cm := NewContractMapper(key)
+	am := account.NewAccountMapper(key)
+	em := NewEnvManager(key, cm, am)
+
+	code, _ := hex.DecodeString(testCode0)
+	owner := common.BytesToAddress([]byte("owner"))
+	addrA := common.BytesToAddress([]byte("A"))
+	addrB := common.BytesToAddress([]byte("B"))
+	cm.PutCode(ctx, code)
+	cm.Put(ctx, addrA, NewContract(owner, addrA, code))
+	cm.Put(ctx, addrB, NewContract(owner, addrB, code))
+
+	env, err := em.Get(ctx, owner, addrA, Args{})
+	assert.NoError(err)
+	ps := NewProcess(env, nil, make(valueT))
+
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.NoError(err)
+	_, err = ps.Call(addrA, []byte("init"), Args{})
+	assert.NoError(err)
+
+	// call depth
+	env.Depth = int(DefaultVMParams().MaxCallDepth)
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.Equal(ErrMaxCallDepthExceeded, err)
+	env.Depth = 0
+	// the max call depth is configured in the params of the chain
+	env.Depth = 1
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.NoError(err)
+	params := DefaultVMParams()
+	params.MaxCallDepth = 1
+	assert.NoError(em.SetParams(ctx, params))
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.Equal(ErrMaxCallDepthExceeded, err)
+	assert.NoError(em.SetParams(ctx, DefaultVMParams()))
+	env.Depth = 0
+
+	// reentrancy lock
+	ps.LockReentrancy()
+	_, err = ps.Call(addrA, []byte("init"), Args{})
+	assert.Equal(ErrReentrantCall, err)
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.NoError(err)
+
+	// the callee of static call cannot modify any state
+	_, err = ps.StaticCall(addrB, []byte("init"), Args{})
+	assert.NoError(err)
+	env.ReadOnly = true
+	assert.Equal(ErrReadOnly, ps.Transfer(addrB, 0))
+	assert.Equal(ErrReadOnly, ps.EmitEvent(&event.Entry{Name: []byte("name"), Value: []byte("value")}))
+	_, err = ps.CreateContract(code, Args{})
+	assert.Equal(ErrReadOnly, err)
+
+	callee, err := em.Get(ctx, addrA, addrB, Args{})
+	assert.NoError(err)
+	assert.NoError(callee.setCaller(env, false))
+	assert.True(callee.ReadOnly)
+	assert.Error(callee.DB.Set([]byte("key"), []byte("value")))
+}
+
+// init transfers 10 to the address "to", and then returns -1
+const testCodeTransferFail = "0061736d01000000010c0260037f7f7e017f6000017f02120103656e760a5f5f7472616e73666572000003020101050301000107080104696e697400010a0f010d0041004114420a10001a417f0b0b1a010041000b14000000000000000000000000000000000000746f"
+
+func TestNestedCallRollback(t *testing.T) {
+	assert := assert.New(t)
+
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil).WithGasMeter(sdk.NewGasMeter(1000000))
+	cm := NewContractMapper(key)
+	am := account.NewAccountMapper(key)
+	em := NewEnvManager(key, cm, am)
+
+	owner := common.BytesToAddress([]byte("owner"))
+	to := common.BytesToAddress([]byte("to"))
+	addrA := common.BytesToAddress([]byte("A"))
+	addrB := common.BytesToAddress([]byte("B"))
+	codeA, _ := hex.DecodeString(testCode0)
+	codeB, _ := hex.DecodeString(testCodeTransferFail)
+	cm.PutCode(ctx, codeA)
+	cm.PutCode(ctx, codeB)
+	cm.Put(ctx, addrA, NewContract(owner, addrA, codeA))
+	cm.Put(ctx, addrB, NewContract(owner, addrB, codeB))
+	_, err = am.AddBalance(ctx, addrB, 100)
+	assert.NoError(err)
+
+	env, err := em.Get(ctx, owner, addrA, Args{})
+	assert.NoError(err)
+	ps := NewProcess(env, nil, make(valueT))
+
+	// the transfer of the failed callee is discarded
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.Error(err)
+	b, err := am.GetBalance(ctx, addrB)
+	assert.NoError(err)
+	assert.EqualValues(100, b)
+	_, err = am.GetBalance(ctx, to)
+	assert.Equal(account.ErrAccountNotFound, err)
+}
+
+func TestNestedCallPermission(t *testing.T) {
+	assert := assert.New(t)
+
+	key := sdk.NewKVStoreKey("main")
+	// the chain keeps the permissions in another store, because their prefixes overlap with the ones of the contracts
+	pkey := sdk.NewKVStoreKey("permission")
+	cms, err := testutil.GetTestCommitMultiStore(key, pkey)
+	assert.NoError(err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	cm := NewContractMapper(key)
+	am := account.NewAccountMapper(key)
+	pm := permission.NewPermissionMapper(pkey)
+	em := NewEnvManager(key, cm, am)
+	em.SetPermissionMapper(pm)
+
+	code, _ := hex.DecodeString(testCode0)
+	owner := common.BytesToAddress([]byte("owner"))
+	sender := common.BytesToAddress([]byte("sender"))
+	addrA := common.BytesToAddress([]byte("A"))
+	addrB := common.BytesToAddress([]byte("B"))
+	cm.PutCode(ctx, code)
+	cm.Put(ctx, addrA, NewContract(owner, addrA, code))
+	cm.Put(ctx, addrB, NewContract(owner, addrB, code))
+
+	env, err := em.Get(ctx, sender, addrA, Args{})
+	assert.NoError(err)
+	ps := NewProcess(env, nil, make(valueT))
+	// B is called by A in the tx of the sender
+	nested, err := em.Get(ctx, addrA, addrB, Args{})
+	assert.NoError(err)
+	assert.NoError(nested.setCaller(env, false))
+	nps := NewProcess(nested, nil, make(valueT))
+
+	// the chain is permissionless until the permissions are enabled
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.NoError(err)
+	_, err = ps.CreateContract(code, Args{})
+	assert.NoError(err)
+
+	// the roles of the calling contracts are not used
+	pm.Enable(ctx)
+	pm.SetRole(ctx, addrA, permission.RoleAdmin, true)
+	pm.SetRole(ctx, addrB, permission.RoleAdmin, true)
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.Equal(ErrPermissionDenied, err)
+	_, err = ps.StaticCall(addrB, []byte("init"), Args{})
+	assert.Equal(ErrPermissionDenied, err)
+	_, err = nps.Call(addrA, []byte("init"), Args{})
+	assert.Equal(ErrPermissionDenied, err)
+	_, err = ps.CreateContract(code, Args{})
+	assert.Equal(ErrPermissionDenied, err)
+	h := cm.PutCode(ctx, code)
+	_, err = ps.CreateContractFromHash(h, Args{})
+	assert.Equal(ErrPermissionDenied, err)
+	_, err = nps.CreateContract(code, Args{})
+	assert.Equal(ErrPermissionDenied, err)
+
+	// the roles of the sender of the tx are checked at every depth
+	pm.SetRole(ctx, sender, permission.RoleCaller, true)
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.NoError(err)
+	_, err = nps.Call(addrA, []byte("init"), Args{})
+	assert.NoError(err)
+
+	// the caller list of the restricted callee applies to the sender of the tx
+	pm.SetContractCaller(ctx, addrB, addrA, true)
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.Equal(ErrPermissionDenied, err)
+	pm.SetContractCaller(ctx, addrB, sender, true)
+	_, err = ps.Call(addrB, []byte("init"), Args{})
+	assert.NoError(err)
+
+	pm.SetRole(ctx, sender, permission.RoleDeployer, true)
+	_, err = ps.CreateContract(code, Args{})
+	assert.NoError(err)
+	_, err = nps.CreateContract(code, Args{})
+	assert.NoError(err)
+}
+
+func TestCallError(t *testing.T) {
+	assert := assert.New(t)
+
+	key := sdk.NewKVStoreKey("main")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(err)
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	cm := NewContractMapper(key)
+	em := NewEnvManager(key, cm, account.NewAccountMapper(key))
+
+	owner := common.BytesToAddress([]byte("owner"))
+	var put = func(name, code string) common.Address {
+		addr := common.BytesToAddress([]byte(name))
+		b, _ := hex.DecodeString(code)
+		cm.PutCode(ctx, b)
+		cm.Put(ctx, addr, NewContract(owner, addr, b))
+		return addr
+	}
+	addrA := put("A", testCode0)
+	addrFail := put("fail", testCodeFail)
+	addrRevert := put("revert", testCodeRevert)
+
+	env, err := em.Get(ctx, owner, addrA, Args{})
+	assert.NoError(err)
+	vt := make(valueT)
+	ps := NewProcess(env, nil, vt)
+
+	// the caller can read the exit code of the callee
+	_, err = ps.Call(addrFail, []byte("init"), Args{})
+	assert.Error(err)
+	assert.Equal(&CallError{Code: -2, Message: ""}, ps.CallError())
+	id := GetCallError(ps)
+	v, err := vt.Get(id)
+	assert.NoError(err)
+	assert.Equal([]byte{0xff, 0xff, 0xff, 0xfe}, v)
+
+	_, err = ps.Call(common.BytesToAddress([]byte("unknown")), []byte("init"), Args{})
+	assert.Equal(ErrContractNotFound, err)
+	assert.Equal(&CallError{Code: CodeCallFailed, Message: ErrContractNotFound.Error()}, ps.CallError())
+
+	_, err = ps.Call(addrA, []byte("init"), Args{})
+	assert.NoError(err)
+	assert.Nil(ps.CallError())
+	assert.Equal(-1, GetCallError(ps))
+
+	// revert aborts the caller too
+	assert.Panics(func() {
+		ps.Call(addrRevert, []byte("init"), Args{})
+	})
+
+	// Exec returns RevertError at the top-level call
+	env, err = em.Get(ctx, owner, addrRevert, Args{})
+	assert.NoError(err)
+	_, err = env.Exec(ctx, "init")
+	assert.Equal(&RevertError{Address: addrRevert.Hex(), Code: 5, Message: ""}, err)
+}
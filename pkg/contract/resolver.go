@@ -2,8 +2,6 @@ package contract
 
 import (
 	"fmt"
-
-	"github.com/perlin-network/life/exec"
 )
 
 type Resolver struct {
@@ -15,130 +13,209 @@ func NewResolver(env *Env) *Resolver {
 	return &Resolver{env: env, vt: make(valueT)}
 }
 
-func (r *Resolver) withProcess(cb func(*exec.VirtualMachine, Process) int64) exec.FunctionImport {
-	return func(vm *exec.VirtualMachine) int64 {
+func (r *Resolver) withProcess(field string, cb func(mem []byte, args []int64, ps Process) int64) HostFunction {
+	return func(mem []byte, args []int64) int64 {
+		r.env.gasMeter.ConsumeGas(GasPerHostCall, field)
+		if r.env.debug != nil {
+			r.env.debug.addHostCall(HostCall{
+				Contract: r.env.Contract.Address(),
+				Name:     field,
+				Args:     append([]int64(nil), args...),
+			})
+		}
 		ps := NewProcess(r.env, r.env.Logger, r.vt)
-		return cb(vm, ps)
+		return cb(mem, args, ps)
 	}
 }
 
-// ResolveFunc defines a set of import functions that may be called within a WebAssembly module.
-func (r *Resolver) ResolveFunc(module, field string) exec.FunctionImport {
-	switch module {
-	case "env":
-		switch field {
-		case "__get_sender":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				w := NewWriter(vm.Memory, cf.Locals[0], cf.Locals[1])
-				return int64(GetSender(ps, w))
-			})
-		case "__get_contract_address":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				w := NewWriter(vm.Memory, cf.Locals[0], cf.Locals[1])
-				return int64(GetContractAddress(ps, w))
-			})
-		case "__get_arg":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				idx := int(cf.Locals[0])
-				offset := int(cf.Locals[1])
-				w := NewWriter(vm.Memory, cf.Locals[2], cf.Locals[3])
-				return int64(GetArg(ps, idx, offset, w))
-			})
-		case "__read_state":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				key := NewReader(vm.Memory, cf.Locals[0], cf.Locals[1])
-				offset := int(cf.Locals[2])
-				buf := NewWriter(vm.Memory, cf.Locals[3], cf.Locals[4])
-				return int64(ReadState(ps, key, offset, buf))
-			})
-		case "__write_state":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				key := NewReader(vm.Memory, cf.Locals[0], cf.Locals[1])
-				val := NewReader(vm.Memory, cf.Locals[2], cf.Locals[3])
-				return int64(WriteState(ps, key, val))
-			})
-		case "__log":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				msg := NewReader(vm.Memory, cf.Locals[0], cf.Locals[1])
-				return int64(Log(ps, msg))
-			})
-		case "__set_response":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				return int64(SetResponse(ps, NewReader(vm.Memory, cf.Locals[0], cf.Locals[1])))
-			})
-		case "__call_contract":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				addr := NewReader(vm.Memory, cf.Locals[0], cf.Locals[1])
-				entry := NewReader(vm.Memory, cf.Locals[2], cf.Locals[3])
-				argb := NewReader(vm.Memory, cf.Locals[4], cf.Locals[5])
-				return int64(CallContract(ps, addr, entry, argb))
-			})
-		case "__read":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				id := int(cf.Locals[0])
-				offset := int(cf.Locals[1])
-				buf := NewWriter(vm.Memory, cf.Locals[2], cf.Locals[3])
-				return int64(Read(ps, id, offset, buf))
-			})
-		case "__keccak256":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				msg := NewReader(vm.Memory, cf.Locals[0], cf.Locals[1])
-				buf := NewWriter(vm.Memory, cf.Locals[2], cf.Locals[3])
-				return int64(Keccak256(ps, msg, buf))
-			})
-		case "__sha256":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				msg := NewReader(vm.Memory, cf.Locals[0], cf.Locals[1])
-				buf := NewWriter(vm.Memory, cf.Locals[2], cf.Locals[3])
-				return int64(Sha256(ps, msg, buf))
-			})
-		case "__ecrecover":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				h := NewReader(vm.Memory, cf.Locals[0], cf.Locals[1])
-				v := NewReader(vm.Memory, cf.Locals[2], cf.Locals[3])
-				r := NewReader(vm.Memory, cf.Locals[4], cf.Locals[5])
-				s := NewReader(vm.Memory, cf.Locals[6], cf.Locals[7])
-				ret := NewWriter(vm.Memory, cf.Locals[8], cf.Locals[9])
-				return int64(ECRecover(ps, h, v, r, s, ret))
-			})
-		case "__ecrecover_address":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				h := NewReader(vm.Memory, cf.Locals[0], cf.Locals[1])
-				v := NewReader(vm.Memory, cf.Locals[2], cf.Locals[3])
-				r := NewReader(vm.Memory, cf.Locals[4], cf.Locals[5])
-				s := NewReader(vm.Memory, cf.Locals[6], cf.Locals[7])
-				ret := NewWriter(vm.Memory, cf.Locals[8], cf.Locals[9])
-				return int64(ECRecoverAddress(ps, h, v, r, s, ret))
-			})
-		case "__emit_event":
-			return r.withProcess(func(vm *exec.VirtualMachine, ps Process) int64 {
-				cf := vm.GetCurrentFrame()
-				name := NewReader(vm.Memory, cf.Locals[0], cf.Locals[1])
-				value := NewReader(vm.Memory, cf.Locals[2], cf.Locals[3])
-				return int64(EmitEvent(ps, name, value))
-			})
-		default:
-			panic(fmt.Errorf("unknown field: %s", field))
-		}
-	default:
-		panic(fmt.Errorf("unknown module: %s", module))
+// Resolve returns the host function that may be called within a WebAssembly module.
+func (r *Resolver) Resolve(module, field string) (HostFunction, error) {
+	if module != "env" {
+		return nil, fmt.Errorf("unknown module: %s", module)
+	}
+	if f := r.hostFunction(field); f != nil {
+		return f, nil
 	}
+	return nil, fmt.Errorf("unknown field: %s", field)
 }
 
-// ResolveGlobal defines a set of global variables for use within a WebAssembly module.
-func (r *Resolver) ResolveGlobal(module, field string) int64 {
-	panic(fmt.Errorf("not supported module: %s %s", module, field))
+func (r *Resolver) hostFunction(field string) HostFunction {
+	switch field {
+	case "__get_sender":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			w := NewWriter(mem, args[0], args[1])
+			return int64(GetSender(ps, w))
+		})
+	case "__get_contract_address":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			w := NewWriter(mem, args[0], args[1])
+			return int64(GetContractAddress(ps, w))
+		})
+	case "__get_block_height":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			return GetBlockHeight(ps)
+		})
+	case "__get_block_time":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			return GetBlockTime(ps)
+		})
+	case "__get_chain_id":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			w := NewWriter(mem, args[0], args[1])
+			return int64(GetChainID(ps, w))
+		})
+	case "__get_proposer":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			w := NewWriter(mem, args[0], args[1])
+			return int64(GetProposer(ps, w))
+		})
+	case "__get_tx_hash":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			w := NewWriter(mem, args[0], args[1])
+			return int64(GetTxHash(ps, w))
+		})
+	case "__get_balance":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			addr := NewReader(mem, args[0], args[1])
+			w := NewWriter(mem, args[2], args[3])
+			return int64(GetBalance(ps, addr, w))
+		})
+	case "__get_value":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			w := NewWriter(mem, args[0], args[1])
+			return int64(GetValue(ps, w))
+		})
+	case "__transfer":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			to := NewReader(mem, args[0], args[1])
+			return int64(Transfer(ps, to, uint64(args[2])))
+		})
+	case "__get_arg":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			idx := int(args[0])
+			offset := int(args[1])
+			w := NewWriter(mem, args[2], args[3])
+			return int64(GetArg(ps, idx, offset, w))
+		})
+	case "__read_state":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			key := NewReader(mem, args[0], args[1])
+			offset := int(args[2])
+			buf := NewWriter(mem, args[3], args[4])
+			return int64(ReadState(ps, key, offset, buf))
+		})
+	case "__write_state":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			key := NewReader(mem, args[0], args[1])
+			val := NewReader(mem, args[2], args[3])
+			return int64(WriteState(ps, key, val))
+		})
+	case "__delete_state":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			key := NewReader(mem, args[0], args[1])
+			return int64(DeleteState(ps, key))
+		})
+	case "__iterate_state":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			start := NewReader(mem, args[0], args[1])
+			end := NewReader(mem, args[2], args[3])
+			limit := int(args[4])
+			return int64(IterateState(ps, start, end, limit))
+		})
+	case "__log":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			msg := NewReader(mem, args[0], args[1])
+			return int64(Log(ps, msg))
+		})
+	case "__set_response":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			return int64(SetResponse(ps, NewReader(mem, args[0], args[1])))
+		})
+	case "__call_contract":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			addr := NewReader(mem, args[0], args[1])
+			entry := NewReader(mem, args[2], args[3])
+			argb := NewReader(mem, args[4], args[5])
+			return int64(CallContract(ps, addr, entry, argb))
+		})
+	case "__static_call":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			addr := NewReader(mem, args[0], args[1])
+			entry := NewReader(mem, args[2], args[3])
+			argb := NewReader(mem, args[4], args[5])
+			return int64(StaticCallContract(ps, addr, entry, argb))
+		})
+	case "__lock_reentrancy":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			return int64(LockReentrancy(ps))
+		})
+	case "__get_call_error":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			return int64(GetCallError(ps))
+		})
+	case "__revert":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			msg := NewReader(mem, args[1], args[2])
+			return int64(Revert(ps, uint32(args[0]), msg))
+		})
+	case "__create_contract":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			code := NewReader(mem, args[0], args[1])
+			argb := NewReader(mem, args[2], args[3])
+			ret := NewWriter(mem, args[4], args[5])
+			return int64(CreateContract(ps, code, argb, ret))
+		})
+	case "__create_contract_from_hash":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			hash := NewReader(mem, args[0], args[1])
+			argb := NewReader(mem, args[2], args[3])
+			ret := NewWriter(mem, args[4], args[5])
+			return int64(CreateContractFromHash(ps, hash, argb, ret))
+		})
+	case "__read":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			id := int(args[0])
+			offset := int(args[1])
+			buf := NewWriter(mem, args[2], args[3])
+			return int64(Read(ps, id, offset, buf))
+		})
+	case "__keccak256":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			msg := NewReader(mem, args[0], args[1])
+			buf := NewWriter(mem, args[2], args[3])
+			return int64(Keccak256(ps, msg, buf))
+		})
+	case "__sha256":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			msg := NewReader(mem, args[0], args[1])
+			buf := NewWriter(mem, args[2], args[3])
+			return int64(Sha256(ps, msg, buf))
+		})
+	case "__ecrecover":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			h := NewReader(mem, args[0], args[1])
+			v := NewReader(mem, args[2], args[3])
+			r := NewReader(mem, args[4], args[5])
+			s := NewReader(mem, args[6], args[7])
+			ret := NewWriter(mem, args[8], args[9])
+			return int64(ECRecover(ps, h, v, r, s, ret))
+		})
+	case "__ecrecover_address":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			h := NewReader(mem, args[0], args[1])
+			v := NewReader(mem, args[2], args[3])
+			r := NewReader(mem, args[4], args[5])
+			s := NewReader(mem, args[6], args[7])
+			ret := NewWriter(mem, args[8], args[9])
+			return int64(ECRecoverAddress(ps, h, v, r, s, ret))
+		})
+	case "__emit_event":
+		return r.withProcess(field, func(mem []byte, args []int64, ps Process) int64 {
+			name := NewReader(mem, args[0], args[1])
+			value := NewReader(mem, args[2], args[3])
+			return int64(EmitEvent(ps, name, value))
+		})
+	default:
+		return nil
+	}
 }
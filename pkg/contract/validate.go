@@ -0,0 +1,211 @@
+package contract
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/go-interpreter/wagon/disasm"
+	"github.com/go-interpreter/wagon/wasm"
+	"github.com/perlin-network/life/compiler"
+)
+
+const (
+	// MaxCodeSize is the max size of contract code
+	MaxCodeSize = 1024 * 1024
+	// MaxMemoryPages is the max number of memory pages that contract can use
+	MaxMemoryPages = 512
+)
+
+type hostFunctionSig struct {
+	params  []wasm.ValueType
+	returns []wasm.ValueType
+}
+
+func sig(returns wasm.ValueType, params ...wasm.ValueType) hostFunctionSig {
+	return hostFunctionSig{params: params, returns: []wasm.ValueType{returns}}
+}
+
+func i32s(n int) []wasm.ValueType {
+	ts := make([]wasm.ValueType, n)
+	for i := range ts {
+		ts[i] = typeI32
+	}
+	return ts
+}
+
+const (
+	typeI32 = wasm.ValueTypeI32
+	typeI64 = wasm.ValueTypeI64
+)
+
+// hostFunctions is a set of functions that Resolver provides to contracts
+var hostFunctions = map[string]hostFunctionSig{
+	"__get_sender":                sig(typeI32, i32s(2)...),
+	"__get_contract_address":      sig(typeI32, i32s(2)...),
+	"__get_block_height":          sig(typeI64),
+	"__get_block_time":            sig(typeI64),
+	"__get_chain_id":              sig(typeI32, i32s(2)...),
+	"__get_proposer":              sig(typeI32, i32s(2)...),
+	"__get_tx_hash":               sig(typeI32, i32s(2)...),
+	"__get_balance":               sig(typeI32, i32s(4)...),
+	"__get_value":                 sig(typeI32, i32s(2)...),
+	"__transfer":                  sig(typeI32, typeI32, typeI32, typeI64),
+	"__get_arg":                   sig(typeI32, i32s(4)...),
+	"__read_state":                sig(typeI32, i32s(5)...),
+	"__write_state":               sig(typeI32, i32s(4)...),
+	"__delete_state":              sig(typeI32, i32s(2)...),
+	"__iterate_state":             sig(typeI32, i32s(5)...),
+	"__log":                       sig(typeI32, i32s(2)...),
+	"__set_response":              sig(typeI32, i32s(2)...),
+	"__call_contract":             sig(typeI32, i32s(6)...),
+	"__static_call":               sig(typeI32, i32s(6)...),
+	"__lock_reentrancy":           sig(typeI32),
+	"__get_call_error":            sig(typeI32),
+	"__revert":                    sig(typeI32, i32s(3)...),
+	"__create_contract":           sig(typeI32, i32s(6)...),
+	"__create_contract_from_hash": sig(typeI32, i32s(6)...),
+	"__read":                      sig(typeI32, i32s(4)...),
+	"__keccak256":                 sig(typeI32, i32s(4)...),
+	"__sha256":                    sig(typeI32, i32s(4)...),
+	"__ecrecover":                 sig(typeI32, i32s(10)...),
+	"__ecrecover_address":         sig(typeI32, i32s(10)...),
+	"__emit_event":                sig(typeI32, i32s(4)...),
+}
+
+func (s hostFunctionSig) equal(fs wasm.FunctionSig) bool {
+	return equalValueTypes(s.params, fs.ParamTypes) && equalValueTypes(s.returns, fs.ReturnTypes)
+}
+
+func equalValueTypes(a, b []wasm.ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateCode validates that the code is a wasm module which can be executed as a contract.
+func ValidateCode(code []byte) (err error) {
+	if len(code) == 0 {
+		return errors.New("code is empty")
+	} else if len(code) > MaxCodeSize {
+		return fmt.Errorf("code size %v exceeds the limit %v", len(code), MaxCodeSize)
+	}
+	// the wasm parser may panic on malformed input
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid module: %v", r)
+		}
+	}()
+
+	m, err := compiler.LoadModule(code)
+	if err != nil {
+		return fmt.Errorf("invalid module: %v", err)
+	}
+	if err := validateImports(m.Base); err != nil {
+		return err
+	}
+	if err := validateMemory(m.Base); err != nil {
+		return err
+	}
+	if err := validateNoFloat(m.Base); err != nil {
+		return err
+	}
+	return validateExports(m.Base)
+}
+
+func validateImports(m *wasm.Module) error {
+	if m.Import == nil {
+		return nil
+	}
+	for _, imp := range m.Import.Entries {
+		fi, ok := imp.Type.(wasm.FuncImport)
+		if !ok {
+			return fmt.Errorf("unsupported import kind: %v.%v", imp.ModuleName, imp.FieldName)
+		}
+		if imp.ModuleName != "env" {
+			return fmt.Errorf("unknown import module: %v", imp.ModuleName)
+		}
+		s, ok := hostFunctions[imp.FieldName]
+		if !ok {
+			return fmt.Errorf("unknown host function: %v", imp.FieldName)
+		}
+		if int(fi.Type) >= len(m.Types.Entries) || !s.equal(m.Types.Entries[fi.Type]) {
+			return fmt.Errorf("host function signature mismatch: %v", imp.FieldName)
+		}
+	}
+	return nil
+}
+
+func validateMemory(m *wasm.Module) error {
+	if m.Memory == nil {
+		return nil
+	}
+	for _, mem := range m.Memory.Entries {
+		if mem.Limits.Initial > MaxMemoryPages {
+			return fmt.Errorf("initial memory pages %v exceeds the limit %v", mem.Limits.Initial, MaxMemoryPages)
+		}
+	}
+	return nil
+}
+
+// validateNoFloat rejects floating-point types and opcodes, whose results may be non-deterministic across platforms.
+func validateNoFloat(m *wasm.Module) error {
+	if m.Types != nil {
+		for _, fs := range m.Types.Entries {
+			if hasFloat(fs.ParamTypes...) || hasFloat(fs.ReturnTypes...) {
+				return fmt.Errorf("floating-point type is not allowed: %v", fs)
+			}
+		}
+	}
+	if m.Global != nil {
+		for _, g := range m.Global.Globals {
+			if hasFloat(g.Type.Type) {
+				return errors.New("floating-point global is not allowed")
+			}
+		}
+	}
+	for i, f := range m.FunctionIndexSpace {
+		for _, l := range f.Body.Locals {
+			if hasFloat(l.Type) {
+				return fmt.Errorf("floating-point local is not allowed: function=%v", i)
+			}
+		}
+		d, err := disasm.Disassemble(f, m)
+		if err != nil {
+			return fmt.Errorf("invalid function: function=%v err=%v", i, err)
+		}
+		for _, instr := range d.Code {
+			if instr.Op.Polymorphic {
+				continue
+			}
+			if hasFloat(instr.Op.Returns) || hasFloat(instr.Op.Args...) {
+				return fmt.Errorf("floating-point opcode is not allowed: function=%v op=%v", i, instr.Op.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func hasFloat(ts ...wasm.ValueType) bool {
+	for _, t := range ts {
+		if t == wasm.ValueTypeF32 || t == wasm.ValueTypeF64 {
+			return true
+		}
+	}
+	return false
+}
+
+func validateExports(m *wasm.Module) error {
+	if m.Export != nil {
+		if e, ok := m.Export.Entries[transaction.ContractInitFunc]; ok && e.Kind == wasm.ExternalFunction {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%v' function must be exported", transaction.ContractInitFunc)
+}
@@ -0,0 +1,51 @@
+package contract
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCode(t *testing.T) {
+	var cases = []struct {
+		code  string
+		valid bool
+	}{
+		// imports __set_response and exports init
+		{"0061736d01000000010b0260027f7f017f6000017f02160103656e760e5f5f7365745f726573706f6e7365000003020101050301000107080104696e697400010a0601040041000b", true},
+		// imports an unknown host function
+		{"0061736d01000000010b0260027f7f017f6000017f02110103656e76095f5f756e6b6e6f776e000003020101050301000107080104696e697400010a0601040041000b", false},
+		// imports a function from an unknown module
+		{"0061736d01000000010b0260027f7f017f6000017f021601036578740e5f5f7365745f726573706f6e7365000003020101050301000107080104696e697400010a0601040041000b", false},
+		// imports __set_response with a wrong signature
+		{"0061736d01000000010a0260017f017f6000017f02160103656e760e5f5f7365745f726573706f6e7365000003020101050301000107080104696e697400010a0601040041000b", false},
+		// init uses f32.const
+		{"0061736d01000000010b0260027f7f017f6000017f02160103656e760e5f5f7365745f726573706f6e7365000003020101050301000107080104696e697400010a0c010a0043000000001a41000b", false},
+		// init is not exported
+		{"0061736d01000000010b0260027f7f017f6000017f02160103656e760e5f5f7365745f726573706f6e73650000030201010503010001070801046d61696e00010a0601040041000b", false},
+		// initial memory pages exceed the limit
+		{"0061736d01000000010b0260027f7f017f6000017f02160103656e760e5f5f7365745f726573706f6e736500000302010105040100e80707080104696e697400010a0601040041000b", false},
+		// not a wasm module
+		{hex.EncodeToString([]byte("code")), false},
+		// truncated module
+		{"0061736d01000000010b0260027f7f017f6000017f0216", false},
+		{"", false},
+	}
+
+	for i, cs := range cases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			code, err := hex.DecodeString(cs.code)
+			assert.NoError(t, err)
+			err = ValidateCode(code)
+			if cs.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+
+	assert.Error(t, ValidateCode(make([]byte, MaxCodeSize+1)))
+}
@@ -10,10 +10,13 @@ import (
 
 const (
 	VersionSize = 8
+	// MaxIterateLimit is the max number of items that Iterate returns at once
+	MaxIterateLimit = 1000
 )
 
 var (
 	ErrKeyNotFound = errors.New("key not found")
+	ErrReadOnly    = errors.New("db is read-only")
 )
 
 var _ StateDB = new(VersionedDB)
@@ -21,6 +24,14 @@ var _ StateDB = new(VersionedDB)
 type StateDB interface {
 	Set(k, v []byte) error
 	Get(k []byte) ([]byte, error)
+	Delete(k []byte) error
+	Iterate(start, end []byte, limit int) ([]KV, error)
+}
+
+// KV is a key-value pair in the state
+type KV struct {
+	Key   []byte
+	Value []byte
 }
 
 type Version struct {
@@ -46,8 +57,9 @@ func MakeVersion(b []byte) (Version, error) {
 }
 
 type VersionedDB struct {
-	store types.KVStore
-	rwm   *RWSetMap
+	store    types.KVStore
+	rwm      *RWSetMap
+	readOnly bool
 }
 
 func NewVersionedDB(store types.KVStore) *VersionedDB {
@@ -57,6 +69,11 @@ func NewVersionedDB(store types.KVStore) *VersionedDB {
 	}
 }
 
+// SetReadOnly makes Set and Delete fail with ErrReadOnly
+func (db *VersionedDB) SetReadOnly(readOnly bool) {
+	db.readOnly = readOnly
+}
+
 func (db *VersionedDB) get(k []byte) (*ValueObject, error) {
 	b := db.store.Get(k)
 	if b == nil {
@@ -66,6 +83,9 @@ func (db *VersionedDB) get(k []byte) (*ValueObject, error) {
 }
 
 func (db *VersionedDB) Set(k, v []byte) error {
+	if db.readOnly {
+		return ErrReadOnly
+	}
 	db.rwm.AddWrite(k, v)
 	return nil
 }
@@ -74,9 +94,14 @@ func (db *VersionedDB) set(k, v []byte, version Version) {
 	db.store.Set(k, (&ValueObject{Value: v, Version: version}).Marshal())
 }
 
+// Get returns the value of the key. Even if the key doesn't exist, the read is recorded
+// so that the tx is invalidated when another tx creates the key before it is committed.
 func (db *VersionedDB) Get(k []byte) ([]byte, error) {
 	vo, err := db.get(k)
-	if err != nil {
+	if err == ErrKeyNotFound {
+		db.rwm.AddAbsentRead(k)
+		return nil, err
+	} else if err != nil {
 		return nil, err
 	}
 	if _, ok := db.rwm.GetRead(k); !ok {
@@ -85,6 +110,48 @@ func (db *VersionedDB) Get(k []byte) ([]byte, error) {
 	return vo.Value, nil
 }
 
+// Delete removes the key. The deletion is recorded as a tombstone write.
+func (db *VersionedDB) Delete(k []byte) error {
+	if db.readOnly {
+		return ErrReadOnly
+	}
+	db.rwm.AddDelete(k)
+	return nil
+}
+
+// Iterate returns key-value pairs whose keys are in [start, end) in ascending order.
+// At most limit pairs are returned, and limit is bounded by MaxIterateLimit.
+// Each returned key is recorded as a read, and the scanned range is recorded as a range read.
+func (db *VersionedDB) Iterate(start, end []byte, limit int) ([]KV, error) {
+	if limit <= 0 || limit > MaxIterateLimit {
+		limit = MaxIterateLimit
+	}
+	it := db.store.Iterator(start, end)
+	defer it.Close()
+
+	var kvs []KV
+	var results []Read
+	scanned := end
+	for ; it.Valid(); it.Next() {
+		if len(kvs) == limit {
+			scanned = make([]byte, len(it.Key()))
+			copy(scanned, it.Key())
+			break
+		}
+		vo, err := BytesToValueObject(it.Value())
+		if err != nil {
+			return nil, err
+		}
+		k := make([]byte, len(it.Key()))
+		copy(k, it.Key())
+		db.rwm.AddRead(k, vo.Version)
+		kvs = append(kvs, KV{Key: k, Value: vo.Value})
+		results = append(results, Read{Key: k, Version: vo.Version})
+	}
+	db.rwm.AddRangeRead(start, scanned, results)
+	return kvs, nil
+}
+
 func (db *VersionedDB) RWSetItems() *RWSetItems {
 	return db.rwm.ToItems()
 }
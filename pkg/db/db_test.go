@@ -20,7 +20,21 @@ func TestVersionedDB(t *testing.T) {
 		key   string
 		value string
 	}
+	type di struct {
+		key string
+	}
+	type ii struct {
+		start, end string
+		limit      int
+		expect     []string
+	}
 	type B = []byte
+	var bs = func(s string) []byte {
+		if s == "" {
+			return nil
+		}
+		return []byte(s)
+	}
 
 	const blockHeight uint32 = 1
 
@@ -33,32 +47,75 @@ func TestVersionedDB(t *testing.T) {
 			nil,
 			[]interface{}{ri{"a", ""}, wi{"a", "A"}, ri{"a", ""}, wi{"b", "B"}},
 			RWSetItems{
+				[]Read{{B("a"), Version{}, true}},
+				[]Write{{B("a"), B("A"), false}, {B("b"), B("B"), false}},
 				nil,
-				[]Write{{B("a"), B("A")}, {B("b"), B("B")}},
 			},
 		},
 		{
-			[][]Write{{{B("a"), B("A")}}},
+			[][]Write{{{B("a"), B("A"), false}}},
 			[]interface{}{ri{"a", "A"}, wi{"a", "A1"}},
 			RWSetItems{
-				[]Read{{B("a"), Version{blockHeight, 0}}},
-				[]Write{{B("a"), B("A1")}},
+				[]Read{{B("a"), Version{blockHeight, 0}, false}},
+				[]Write{{B("a"), B("A1"), false}},
+				nil,
 			},
 		},
 		{
-			[][]Write{{{B("a"), B("A")}}, {{B("b"), B("B")}}},
+			[][]Write{{{B("a"), B("A"), false}}, {{B("b"), B("B"), false}}},
 			[]interface{}{ri{"a", "A"}, wi{"a", "A1"}, ri{"b", "B"}},
 			RWSetItems{
-				[]Read{{B("a"), Version{blockHeight, 0}}, {B("b"), Version{blockHeight, 1}}},
-				[]Write{{B("a"), B("A1")}},
+				[]Read{{B("a"), Version{blockHeight, 0}, false}, {B("b"), Version{blockHeight, 1}, false}},
+				[]Write{{B("a"), B("A1"), false}},
+				nil,
 			},
 		},
 		{
-			[][]Write{nil, {{B("a"), B("A")}}, {{B("a"), B("A1")}}, {{B("b"), B("B")}}},
+			[][]Write{nil, {{B("a"), B("A"), false}}, {{B("a"), B("A1"), false}}, {{B("b"), B("B"), false}}},
 			[]interface{}{ri{"a", "A1"}, wi{"a", "A2"}, ri{"b", "B"}},
 			RWSetItems{
-				[]Read{{B("a"), Version{blockHeight, 2}}, {B("b"), Version{blockHeight, 3}}},
-				[]Write{{B("a"), B("A2")}},
+				[]Read{{B("a"), Version{blockHeight, 2}, false}, {B("b"), Version{blockHeight, 3}, false}},
+				[]Write{{B("a"), B("A2"), false}},
+				nil,
+			},
+		},
+		{
+			[][]Write{{{B("a"), B("A"), false}}},
+			[]interface{}{di{"a"}, ri{"a", "A"}},
+			RWSetItems{
+				[]Read{{B("a"), Version{blockHeight, 0}, false}},
+				[]Write{{B("a"), nil, true}},
+				nil,
+			},
+		},
+		{
+			[][]Write{{{B("a"), B("A"), false}}, {{B("a"), nil, true}}},
+			[]interface{}{ri{"a", ""}, wi{"a", "A1"}, di{"a"}},
+			RWSetItems{
+				[]Read{{B("a"), Version{}, true}},
+				[]Write{{B("a"), nil, true}},
+				nil,
+			},
+		},
+		{
+			[][]Write{{{B("a"), B("A"), false}, {B("b"), B("B"), false}, {B("c"), B("C"), false}, {B("d"), B("D"), false}}},
+			[]interface{}{ii{"a", "c", 0, []string{"A", "B"}}},
+			RWSetItems{
+				[]Read{{B("a"), Version{blockHeight, 0}, false}, {B("b"), Version{blockHeight, 0}, false}},
+				nil,
+				[]RangeRead{{B("a"), B("c"), HashRangeResults([]Read{{B("a"), Version{blockHeight, 0}, false}, {B("b"), Version{blockHeight, 0}, false}})}},
+			},
+		},
+		{
+			[][]Write{{{B("a"), B("A"), false}, {B("b"), B("B"), false}, {B("c"), B("C"), false}, {B("d"), B("D"), false}}},
+			[]interface{}{ii{"b", "", 2, []string{"B", "C"}}, ii{"c", "", 0, []string{"C", "D"}}},
+			RWSetItems{
+				[]Read{{B("b"), Version{blockHeight, 0}, false}, {B("c"), Version{blockHeight, 0}, false}, {B("d"), Version{blockHeight, 0}, false}},
+				nil,
+				[]RangeRead{
+					{B("b"), B("d"), HashRangeResults([]Read{{B("b"), Version{blockHeight, 0}, false}, {B("c"), Version{blockHeight, 0}, false}})},
+					{B("c"), nil, HashRangeResults([]Read{{B("c"), Version{blockHeight, 0}, false}, {B("d"), Version{blockHeight, 0}, false}})},
+				},
 			},
 		},
 	}
@@ -89,6 +146,16 @@ func TestVersionedDB(t *testing.T) {
 					}
 				case wi:
 					assert.NoError(vdb.Set([]byte(op.key), []byte(op.value)))
+				case di:
+					assert.NoError(vdb.Delete([]byte(op.key)))
+				case ii:
+					kvs, err := vdb.Iterate(bs(op.start), bs(op.end), op.limit)
+					assert.NoError(err)
+					var values []string
+					for _, kv := range kvs {
+						values = append(values, string(kv.Value))
+					}
+					assert.Equal(op.expect, values)
 				default:
 					t.Fatalf("unknown type %T", op)
 				}
@@ -99,3 +166,20 @@ func TestVersionedDB(t *testing.T) {
 		})
 	}
 }
+
+func TestReadOnlyVersionedDB(t *testing.T) {
+	assert := assert.New(t)
+
+	key := types.NewKVStoreKey("test")
+	cms, err := testutil.GetTestCommitMultiStore(key)
+	assert.NoError(err)
+	ctx := types.NewContext(cms, abci.Header{}, false, nil)
+	vdb := NewVersionedDB(ctx.KVStore(key))
+	vdb.SetReadOnly(true)
+
+	assert.Equal(ErrReadOnly, vdb.Set([]byte("a"), []byte("A")))
+	assert.Equal(ErrReadOnly, vdb.Delete([]byte("a")))
+	_, err = vdb.Iterate(nil, nil, 0)
+	assert.NoError(err)
+	assert.Empty(vdb.RWSetItems().WriteSet)
+}
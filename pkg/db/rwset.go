@@ -1,25 +1,54 @@
 package db
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 
 	"github.com/bluele/hypermint/pkg/util"
 	"github.com/ethereum/go-ethereum/common"
 )
 
+// Read is a key which tx has read, and the version of the value.
+// If the key didn't exist, Absent is true and Version is empty.
 type Read struct {
 	Key     []byte
 	Version Version
+	Absent  bool
 }
 
 type Write struct {
-	Key   []byte
-	Value []byte
+	Key      []byte
+	Value    []byte
+	IsDelete bool
+}
+
+// RangeRead is a key range [Start, End) that tx has iterated.
+// An empty End means the end of the keyspace.
+// Hash is a digest of the keys and versions which tx has read in the range.
+type RangeRead struct {
+	Start []byte
+	End   []byte
+	Hash  []byte
+}
+
+// HashRangeResults returns a digest of the reads which are results of a range query
+func HashRangeResults(rs []Read) []byte {
+	var buf bytes.Buffer
+	var lb [4]byte
+	for _, r := range rs {
+		binary.BigEndian.PutUint32(lb[:], uint32(len(r.Key)))
+		buf.Write(lb[:])
+		buf.Write(r.Key)
+		buf.Write(r.Version.Bytes())
+	}
+	return util.TxHash(buf.Bytes())
 }
 
 type RWSetItems struct {
-	ReadSet  []Read
-	WriteSet []Write
+	ReadSet      []Read
+	WriteSet     []Write
+	RangeReadSet []RangeRead
 }
 
 type RWSet struct {
@@ -85,6 +114,7 @@ type RWSetMap struct {
 	rs   []Read
 	wmap map[string]int
 	ws   []Write
+	rrs  []RangeRead
 }
 
 func NewRWSetMap() *RWSetMap {
@@ -105,9 +135,28 @@ func (m *RWSetMap) AddRead(key []byte, version Version) bool {
 	return true
 }
 
-func (m *RWSetMap) AddWrite(key, value []byte) {
+// AddAbsentRead records that the key didn't exist when tx read it
+func (m *RWSetMap) AddAbsentRead(key []byte) bool {
 	s := string(key)
-	w := Write{Key: key, Value: value}
+	if _, ok := m.rmap[s]; ok {
+		return false
+	}
+	m.rs = append(m.rs, Read{Key: key, Absent: true})
+	m.rmap[s] = len(m.rs) - 1
+	return true
+}
+
+func (m *RWSetMap) AddWrite(key, value []byte) {
+	m.addWrite(Write{Key: key, Value: value})
+}
+
+// AddDelete adds a tombstone write for the key
+func (m *RWSetMap) AddDelete(key []byte) {
+	m.addWrite(Write{Key: key, IsDelete: true})
+}
+
+func (m *RWSetMap) addWrite(w Write) {
+	s := string(w.Key)
 	if idx, ok := m.wmap[s]; ok {
 		m.ws[idx] = w
 	} else {
@@ -116,8 +165,12 @@ func (m *RWSetMap) AddWrite(key, value []byte) {
 	}
 }
 
+func (m *RWSetMap) AddRangeRead(start, end []byte, results []Read) {
+	m.rrs = append(m.rrs, RangeRead{Start: start, End: end, Hash: HashRangeResults(results)})
+}
+
 func (m *RWSetMap) ToItems() *RWSetItems {
-	return &RWSetItems{ReadSet: m.rs, WriteSet: m.ws}
+	return &RWSetItems{ReadSet: m.rs, WriteSet: m.ws, RangeReadSet: m.rrs}
 }
 
 func (m *RWSetMap) GetRead(key []byte) (Read, bool) {
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 
 	sdk "github.com/bluele/hypermint/pkg/abci/types"
@@ -34,15 +36,28 @@ func commitState(addr common.Address, db sdk.KVStore, items *RWSetItems, version
 		if len(wm) > 0 && wm.Has(string(r.Key)) {
 			return fmt.Errorf("ReadSet: conflicted updates exist: address=%v key=%x(%v)", addr, r.Key, string(r.Key))
 		}
+		if err := validateReadVersion(db, r); err != nil {
+			return fmt.Errorf("ReadSet: %v: address=%v key=%x(%v)", err, addr, r.Key, string(r.Key))
+		}
 		rkeys = append(rkeys, string(r.Key))
 	}
 
+	for _, rr := range items.RangeReadSet {
+		if err := validateRangeRead(db, rr); err != nil {
+			return fmt.Errorf("RangeReadSet: %v: address=%v start=%x end=%x", err, addr, rr.Start, rr.End)
+		}
+	}
+
 	wkeys := make([]string, 0, len(items.WriteSet))
 	for _, w := range items.WriteSet {
 		if len(rm) > 0 && rm.Has(string(w.Key)) {
 			return fmt.Errorf("ReadSet: conflicted updates exist: address=%v key=%x(%v)", addr, w.Key, string(w.Key))
 		}
-		db.Set(w.Key, (&ValueObject{Value: w.Value, Version: version}).Marshal())
+		if w.IsDelete {
+			db.Delete(w.Key)
+		} else {
+			db.Set(w.Key, (&ValueObject{Value: w.Value, Version: version}).Marshal())
+		}
 		wkeys = append(wkeys, string(w.Key))
 	}
 
@@ -56,6 +71,48 @@ func commitState(addr common.Address, db sdk.KVStore, items *RWSetItems, version
 	return nil
 }
 
+// validateReadVersion ensures that the version of the key in the store equals the version which the tx read.
+// If the key was absent when the tx read it, the key must still be absent.
+func validateReadVersion(db sdk.KVStore, r Read) error {
+	b := db.Get(r.Key)
+	if b == nil {
+		if r.Absent {
+			return nil
+		}
+		return ErrKeyNotFound
+	} else if r.Absent {
+		return errors.New("key was absent, but it has been created")
+	}
+	vo, err := BytesToValueObject(b)
+	if err != nil {
+		return err
+	}
+	if vo.Version != r.Version {
+		return fmt.Errorf("version mismatch: expected=%v actual=%v", r.Version, vo.Version)
+	}
+	return nil
+}
+
+// validateRangeRead ensures that a re-executed range query returns the same results as the tx read.
+// Only the hash of the results is re-validated: an update by an earlier tx in the block changes the version
+// of the key, and an insert or a delete changes the keys, so either changes the hash.
+func validateRangeRead(db sdk.KVStore, rr RangeRead) error {
+	it := db.Iterator(rr.Start, rr.End)
+	defer it.Close()
+	var results []Read
+	for ; it.Valid(); it.Next() {
+		vo, err := BytesToValueObject(it.Value())
+		if err != nil {
+			return err
+		}
+		results = append(results, Read{Key: it.Key(), Version: vo.Version})
+	}
+	if !bytes.Equal(HashRangeResults(results), rr.Hash) {
+		return errors.New("phantom read detected")
+	}
+	return nil
+}
+
 type KeyMaps struct {
 	Read  map[common.Address]KeyMap
 	Write map[common.Address]KeyMap
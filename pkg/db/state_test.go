@@ -8,6 +8,7 @@ import (
 	"github.com/bluele/hypermint/pkg/testutil"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
 )
 
@@ -194,6 +195,9 @@ func TestCommitState(t *testing.T) {
 			ctx := types.NewContext(cms, abci.Header{}, false, nil)
 
 			db := ctx.KVStore(testStoreKey)
+			for _, set := range cs.sets {
+				setReadKeys(db, set, ver)
+			}
 			akm := NewKeyMaps()
 			err = CommitState(db, cs.sets, ver, akm)
 			if cs.valid {
@@ -206,10 +210,162 @@ func TestCommitState(t *testing.T) {
 
 }
 
+func TestCommitStateReadVersion(t *testing.T) {
+	var addr = common.Address([20]byte{19: 1})
+	v1 := Version{Height: 1, TxIdx: 0}
+	v2 := Version{Height: 2, TxIdx: 0}
+	v3 := Version{Height: 3, TxIdx: 0}
+
+	var cases = []struct {
+		stored *Version
+		read   Read
+		valid  bool
+	}{
+		{&v1, makeRead("k1", v1), true},
+		{&v2, makeRead("k1", v1), false},
+		{nil, makeRead("k1", v1), false},
+		// the key must still be absent if tx read it as absent
+		{nil, Read{Key: []byte("k1"), Absent: true}, true},
+		{&v1, Read{Key: []byte("k1"), Absent: true}, false},
+	}
+
+	testStoreKey := types.NewKVStoreKey("test")
+	for i, cs := range cases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			assert := assert.New(t)
+
+			cms, err := testutil.GetTestCommitMultiStore(testStoreKey)
+			assert.NoError(err)
+			ctx := types.NewContext(cms, abci.Header{}, false, nil)
+
+			db := ctx.KVStore(testStoreKey)
+			set := makeRWSet(addr, cs.read.Version, []Read{cs.read}, []Write{makeWrite("k2", "v")})
+			if cs.stored != nil {
+				setReadKeys(db, set, *cs.stored)
+			}
+			err = CommitState(db, []*RWSet{set}, v3, NewKeyMaps())
+			if cs.valid {
+				assert.NoError(err)
+			} else {
+				assert.Error(err)
+				// a rejected set must not be written
+				assert.Nil(db.Prefix(addr.Bytes()).Get([]byte("k2")))
+			}
+		})
+	}
+}
+
+func TestCommitStateAbsentRead(t *testing.T) {
+	require := require.New(t)
+	var addr = common.Address([20]byte{19: 1})
+	testStoreKey := types.NewKVStoreKey("test")
+	cms, err := testutil.GetTestCommitMultiStore(testStoreKey)
+	require.NoError(err)
+	ctx := types.NewContext(cms, abci.Header{}, false, nil)
+	store := ctx.KVStore(testStoreKey)
+
+	// tx2 reads the key which doesn't exist yet
+	read := func() *RWSet {
+		vdb := NewVersionedDB(store.Prefix(addr.Bytes()))
+		_, err := vdb.Get([]byte("k"))
+		require.Equal(ErrKeyNotFound, err)
+		require.NoError(vdb.Set([]byte("other"), []byte("v")))
+		return &RWSet{Address: addr, Items: vdb.RWSetItems()}
+	}
+	tx1 := makeRWSet(addr, Version{}, nil, []Write{makeWrite("k", "v")})
+
+	// the key is still absent
+	cctx, _ := ctx.CacheContext()
+	require.NoError(CommitState(cctx.KVStore(testStoreKey), []*RWSet{read()}, Version{Height: 1}, NewKeyMaps()))
+
+	// tx1 creates the key earlier in the same block
+	cctx, _ = ctx.CacheContext()
+	tx2 := read()
+	m := NewKeyMaps()
+	require.NoError(CommitState(cctx.KVStore(testStoreKey), []*RWSet{tx1}, Version{Height: 1, TxIdx: 0}, m))
+	require.Error(CommitState(cctx.KVStore(testStoreKey), []*RWSet{tx2}, Version{Height: 1, TxIdx: 1}, m))
+
+	// tx1 creates the key in an earlier block than the one which includes tx2
+	tx2 = read()
+	require.NoError(CommitState(store, []*RWSet{tx1}, Version{Height: 1}, NewKeyMaps()))
+	require.Error(CommitState(store, []*RWSet{tx2}, Version{Height: 2}, NewKeyMaps()))
+	require.Nil(store.Prefix(addr.Bytes()).Get([]byte("other")))
+}
+
+func TestCommitStateRangeRead(t *testing.T) {
+	var addr = common.Address([20]byte{19: 1})
+	v1 := Version{Height: 1, TxIdx: 0}
+	v2 := Version{Height: 2, TxIdx: 0}
+
+	var rangeRead = &RWSet{
+		Address: addr,
+		Items: &RWSetItems{
+			RangeReadSet: []RangeRead{{
+				Start: []byte("a"),
+				End:   []byte("c"),
+				Hash:  HashRangeResults([]Read{makeRead("a", v1), makeRead("b", v1)}),
+			}},
+		},
+	}
+
+	var cases = []struct {
+		stored []string
+		sets   []*RWSet
+		valid  bool
+	}{
+		{[]string{"a", "b", "c"}, []*RWSet{rangeRead}, true},
+		// a key is inserted into the range
+		{[]string{"a", "ab", "b"}, []*RWSet{rangeRead}, false},
+		// a key in the range is deleted
+		{[]string{"a"}, []*RWSet{rangeRead}, false},
+		// an earlier tx in the block updates a key in the range
+		{[]string{"a", "b"}, []*RWSet{makeRWSet(addr, v2, nil, []Write{makeWrite("b", "v")}), rangeRead}, false},
+		// an earlier tx in the block updates a key out of the range
+		{[]string{"a", "b"}, []*RWSet{makeRWSet(addr, v2, nil, []Write{makeWrite("c", "v")}), rangeRead}, true},
+	}
+
+	testStoreKey := types.NewKVStoreKey("test")
+	for i, cs := range cases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			assert := assert.New(t)
+
+			cms, err := testutil.GetTestCommitMultiStore(testStoreKey)
+			assert.NoError(err)
+			ctx := types.NewContext(cms, abci.Header{}, false, nil)
+
+			db := ctx.KVStore(testStoreKey)
+			for _, k := range cs.stored {
+				db.Prefix(addr.Bytes()).Set([]byte(k), (&ValueObject{Value: []byte("init"), Version: v1}).Marshal())
+			}
+			// each tx has its own key maps like StateManager
+			var lastErr error
+			for _, set := range cs.sets {
+				lastErr = CommitState(db, []*RWSet{set}, v2, NewKeyMaps())
+			}
+			if cs.valid {
+				assert.NoError(lastErr)
+			} else {
+				assert.Error(lastErr)
+			}
+		})
+	}
+}
+
+// setReadKeys stores the keys that given set reads with the version
+func setReadKeys(db types.KVStore, set *RWSet, version Version) {
+	for _, r := range set.Items.ReadSet {
+		db.Prefix(set.Address.Bytes()).Set(r.Key, (&ValueObject{Value: []byte("init"), Version: version}).Marshal())
+	}
+}
+
 func makeRWSet(addr common.Address, version Version, rs []Read, ws []Write) *RWSet {
 	rsm := NewRWSetMap()
 	for _, r := range rs {
-		rsm.AddRead(r.Key, r.Version)
+		if r.Absent {
+			rsm.AddAbsentRead(r.Key)
+		} else {
+			rsm.AddRead(r.Key, r.Version)
+		}
 	}
 	for _, w := range ws {
 		rsm.AddWrite(w.Key, w.Value)
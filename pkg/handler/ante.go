@@ -1,14 +1,67 @@
 package handler
 
 import (
+	"fmt"
+	"reflect"
+
 	"github.com/bluele/hypermint/pkg/abci/types"
 	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/permission"
+	"github.com/bluele/hypermint/pkg/transaction"
 )
 
-func NewAnteHandler(am account.AccountMapper) types.AnteHandler {
+// NewAnteHandler returns an AnteHandler which rejects txs whose gas price is lower than minGasPrice in CheckTx
+func NewAnteHandler(am account.AccountMapper, pm permission.PermissionMapper, minGasPrice uint64) types.AnteHandler {
 	return func(
 		ctx types.Context, tt types.Tx, simulate bool,
 	) (_ types.Context, _ types.Result, abort bool) {
-		return ctx, types.Result{}, false
+		tx, ok := tt.(transaction.Transaction)
+		if !ok {
+			errMsg := "Unrecognized Tx type: " + reflect.TypeOf(tt).Name()
+			return ctx, types.ErrUnknownRequest(errMsg).Result(), true
+		}
+		if err := tx.VerifySignature(ctx.ChainID()); err != nil {
+			return ctx, err.Result(), true
+		}
+		if err := checkPermission(ctx, pm, tx); err != nil {
+			return ctx, err.Result(), true
+		}
+		common := tx.GetCommon()
+		if err := checkMinGasPrice(ctx, common, minGasPrice, simulate); err != nil {
+			return ctx, err.Result(), true
+		}
+		ctx = setGasMeter(ctx, common, simulate)
+		if err := checkAndIncrNonce(ctx, am, common); err != nil {
+			return ctx, err.Result(), true
+		}
+		if err := deductFee(ctx, am, common); err != nil {
+			return ctx, err.Result(), true
+		}
+		return ctx, types.Result{GasWanted: common.Gas}, false
+	}
+}
+
+// setGasMeter returns a new context with a gas meter limited by tx.Gas.
+// In simulate mode, the gas meter has no limit so that clients can estimate the gas consumption.
+func setGasMeter(ctx types.Context, tx transaction.CommonTx, simulate bool) types.Context {
+	if simulate {
+		return ctx.WithGasMeter(types.NewInfiniteGasMeter())
+	}
+	return ctx.WithGasMeter(types.NewGasMeter(tx.Gas))
+}
+
+// checkAndIncrNonce ensures that tx.Nonce equals the current nonce of the sender, then increments it.
+// This rejects both stale and duplicate transactions.
+func checkAndIncrNonce(ctx types.Context, am account.AccountMapper, tx transaction.CommonTx) types.Error {
+	nonce, err := am.GetNonce(ctx, tx.From)
+	if err != nil {
+		return transaction.ErrInvalidNonce(transaction.DefaultCodespace, err.Error())
+	}
+	if tx.Nonce != nonce {
+		return transaction.ErrInvalidNonce(transaction.DefaultCodespace, fmt.Sprintf("unexpected nonce: expected=%v actual=%v", nonce, tx.Nonce))
+	}
+	if _, err := am.IncrNonce(ctx, tx.From); err != nil {
+		return transaction.ErrInvalidNonce(transaction.DefaultCodespace, err.Error())
 	}
+	return nil
 }
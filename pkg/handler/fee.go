@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/transaction"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// deductFee deducts the max fee of tx from the sender, and deposits it into the fee collector.
+func deductFee(ctx types.Context, am account.AccountMapper, tx transaction.CommonTx) types.Error {
+	fee := tx.Fee()
+	if fee == 0 {
+		return nil
+	}
+	if _, err := am.SubBalance(ctx, tx.From, fee); err != nil {
+		return types.ErrInsufficientFee(fmt.Sprintf("failed to pay fee=%v: %v", fee, err))
+	}
+	if _, err := am.AddBalance(ctx, account.FeeCollectorAddress, fee); err != nil {
+		return types.ErrInternal(err.Error())
+	}
+	return nil
+}
+
+// NewPostHandler returns a PostHandler which refunds the fee for unused gas to the sender.
+func NewPostHandler(am account.AccountMapper) types.PostHandler {
+	return func(ctx types.Context, tt types.Tx, _ types.Result, simulate bool) types.Error {
+		tx, ok := tt.(transaction.Transaction)
+		if !ok {
+			return nil
+		}
+		c := tx.GetCommon()
+		used := ctx.GasMeter().GasConsumed()
+		if c.GasPrice == 0 || used >= c.Gas {
+			return nil
+		}
+		refund := (c.Gas - used) * c.GasPrice
+		// the refund must not consume the gas of tx
+		ctx = ctx.WithGasMeter(types.NewInfiniteGasMeter())
+		if err := am.Transfer(ctx, account.FeeCollectorAddress, refund, c.From); err != nil {
+			return types.ErrInternal(fmt.Sprintf("failed to refund fee=%v: %v", refund, err))
+		}
+		return nil
+	}
+}
+
+// checkMinGasPrice ensures that tx pays at least the min gas price of the node.
+// The price is local to the node, so it is checked only when the node accepts tx into its mempool.
+func checkMinGasPrice(ctx types.Context, tx transaction.CommonTx, minGasPrice uint64, simulate bool) types.Error {
+	if !ctx.IsCheckTx() || simulate || tx.GasPrice >= minGasPrice {
+		return nil
+	}
+	return types.ErrInsufficientFee(fmt.Sprintf("gas price is lower than the min gas price: %v < %v", tx.GasPrice, minGasPrice))
+}
+
+// NewEndBlocker returns an EndBlocker which moves the fees collected in the block to the fee recipient.
+func NewEndBlocker(am account.AccountMapper) types.EndBlocker {
+	return func(ctx types.Context, req abci.RequestEndBlock) abci.ResponseEndBlock {
+		ctx = ctx.WithGasMeter(types.NewInfiniteGasMeter())
+		if err := distributeFee(ctx, am); err != nil {
+			panic(err)
+		}
+		return abci.ResponseEndBlock{}
+	}
+}
+
+// distributeFee moves the collected fees to the fee recipient.
+// If the fee recipient isn't configured, the fees are kept in the fee collector.
+func distributeFee(ctx types.Context, am account.AccountMapper) error {
+	fee, err := am.GetBalance(ctx, account.FeeCollectorAddress)
+	if err == account.ErrAccountNotFound || fee == 0 {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	recipient, ok := am.GetFeeRecipient(ctx)
+	if !ok {
+		return nil
+	}
+	return am.Transfer(ctx, account.FeeCollectorAddress, fee, recipient)
+}
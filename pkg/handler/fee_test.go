@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
+	"github.com/bluele/hypermint/pkg/testutil"
+	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+var testStoreKey = types.NewKVStoreKey("test")
+
+func newTestContext(t *testing.T, header abci.Header) types.Context {
+	cms, err := testutil.GetTestCommitMultiStore(testStoreKey)
+	require.NoError(t, err)
+	return types.NewContext(cms, header, false, nil)
+}
+
+func TestFee(t *testing.T) {
+	require := require.New(t)
+	proposer := common.BytesToAddress(cmn.RandBytes(20))
+	ctx := newTestContext(t, abci.Header{ProposerAddress: proposer.Bytes()})
+	am := account.NewAccountMapper(testStoreKey)
+
+	sender := common.BytesToAddress(cmn.RandBytes(20))
+	_, err := am.AddBalance(ctx, sender, 1000)
+	require.NoError(err)
+
+	tx := &transaction.TransferTx{
+		Common: transaction.CommonTx{
+			Code:     transaction.TRANSFER,
+			From:     sender,
+			Gas:      100,
+			GasPrice: 3,
+		},
+	}
+
+	// fee must be paid by the sender
+	require.Nil(deductFee(ctx, am, tx.Common))
+	requireBalance(t, ctx, am, sender, 700)
+	requireBalance(t, ctx, am, account.FeeCollectorAddress, 300)
+
+	// sender cannot pay the fee over its balance
+	tx.Common.Gas = 1000
+	require.NotNil(deductFee(ctx, am, tx.Common))
+	requireBalance(t, ctx, am, sender, 700)
+	tx.Common.Gas = 100
+
+	// fee for unused gas must be refunded
+	meter := types.NewGasMeter(tx.Common.Gas)
+	meter.ConsumeGas(40, "test")
+	require.Nil(NewPostHandler(am)(ctx.WithGasMeter(meter), tx, types.Result{}, false))
+	requireBalance(t, ctx, am, sender, 880)
+	requireBalance(t, ctx, am, account.FeeCollectorAddress, 120)
+	require.EqualValues(40, meter.GasConsumed())
+
+	// the refund fails without panic if the fee collector cannot pay it
+	meter = types.NewGasMeter(tx.Common.Gas)
+	require.NotNil(NewPostHandler(am)(ctx.WithGasMeter(meter), tx, types.Result{}, false))
+	requireBalance(t, ctx, am, sender, 880)
+
+	// collected fee is kept until the fee recipient is configured,
+	// because the proposer address is not an account address
+	NewEndBlocker(am)(ctx, abci.RequestEndBlock{})
+	requireBalance(t, ctx, am, account.FeeCollectorAddress, 120)
+	_, err = am.GetBalance(ctx, proposer)
+	require.Equal(account.ErrAccountNotFound, err)
+
+	// collected fee goes to the fee recipient
+	recipient := common.BytesToAddress(cmn.RandBytes(20))
+	am.SetFeeRecipient(ctx, recipient)
+	require.Nil(deductFee(ctx, am, tx.Common))
+	NewEndBlocker(am)(ctx, abci.RequestEndBlock{})
+	requireBalance(t, ctx, am, account.FeeCollectorAddress, 0)
+	requireBalance(t, ctx, am, recipient, 420)
+}
+
+func TestMinGasPrice(t *testing.T) {
+	require := require.New(t)
+	ctx := newTestContext(t, abci.Header{})
+	tx := transaction.CommonTx{GasPrice: 2}
+
+	// the min gas price is checked only in CheckTx
+	require.Nil(checkMinGasPrice(ctx, tx, 3, false))
+	ctx = ctx.WithIsCheckTx(true)
+	require.NotNil(checkMinGasPrice(ctx, tx, 3, false))
+	require.Nil(checkMinGasPrice(ctx, tx, 3, true))
+	require.Nil(checkMinGasPrice(ctx, tx, 2, false))
+	require.Nil(checkMinGasPrice(ctx, transaction.CommonTx{}, 0, false))
+}
+
+func requireBalance(t *testing.T, ctx types.Context, am account.AccountMapper, addr common.Address, expected uint64) {
+	b, err := am.GetBalance(ctx, addr)
+	require.NoError(t, err)
+	require.EqualValues(t, expected, b)
+}
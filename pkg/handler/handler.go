@@ -10,12 +10,14 @@ import (
 	"github.com/bluele/hypermint/pkg/contract"
 	"github.com/bluele/hypermint/pkg/contract/event"
 	"github.com/bluele/hypermint/pkg/db"
+	"github.com/bluele/hypermint/pkg/permission"
 	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/bluele/hypermint/pkg/validator"
 
 	"github.com/tendermint/go-amino"
 )
 
-func NewHandler(txm transaction.TxIndexMapper, am account.AccountMapper, cm *contract.ContractManager, envm *contract.EnvManager, sm *db.StateManager) types.Handler {
+func NewHandler(txm transaction.TxIndexMapper, am account.AccountMapper, cm *contract.ContractManager, envm *contract.EnvManager, sm *db.StateManager, vm validator.ValidatorMapper, pm permission.PermissionMapper) types.Handler {
 	return func(ctx types.Context, tx types.Tx) (res types.Result) {
 		ctx = ctx.WithTxIndex(txm.Get(ctx))
 		defer func() {
@@ -25,9 +27,17 @@ func NewHandler(txm transaction.TxIndexMapper, am account.AccountMapper, cm *con
 		case *transaction.TransferTx:
 			return handleTransferTx(ctx, am, tx)
 		case *transaction.ContractDeployTx:
-			return handleContractDeployTx(ctx, cm, envm, sm, tx)
+			return handleContractDeployTx(ctx, cm, tx)
 		case *transaction.ContractCallTx:
-			return handleContractCallTx(ctx, cm, envm, sm, tx)
+			return handleContractCallTx(ctx, am, cm, envm, sm, tx)
+		case *transaction.ContractInstantiateTx:
+			return handleContractInstantiateTx(ctx, am, cm, envm, sm, tx)
+		case *transaction.ContractUpgradeTx:
+			return handleContractUpgradeTx(ctx, am, cm, envm, sm, tx)
+		case *transaction.ValidatorUpdateTx:
+			return handleValidatorUpdateTx(ctx, vm, tx)
+		case *transaction.AdminTx:
+			return handleAdminTx(ctx, pm, tx)
 		default:
 			errMsg := "Unrecognized Tx type: " + reflect.TypeOf(tx).Name()
 			return types.ErrUnknownRequest(errMsg).Result()
@@ -42,26 +52,60 @@ func handleTransferTx(ctx types.Context, am account.AccountMapper, tx *transacti
 	return types.Result{}
 }
 
-func handleContractDeployTx(ctx types.Context, cm *contract.ContractManager, envm *contract.EnvManager, sm *db.StateManager, tx *transaction.ContractDeployTx) types.Result {
-	addr, err := cm.DeployContract(ctx, tx)
+func handleContractDeployTx(ctx types.Context, cm *contract.ContractManager, tx *transaction.ContractDeployTx) types.Result {
+	h, err := cm.UploadCode(ctx, tx.Code)
 	if err != nil {
 		return transaction.ErrInvalidDeploy(transaction.DefaultCodespace, err.Error()).Result()
 	}
-	return handleContractCallTx(ctx, cm, envm, sm, &transaction.ContractCallTx{
+	return types.Result{Data: h.Bytes()}
+}
+
+func handleContractInstantiateTx(ctx types.Context, am account.AccountMapper, cm *contract.ContractManager, envm *contract.EnvManager, sm *db.StateManager, tx *transaction.ContractInstantiateTx) types.Result {
+	addr, err := cm.InstantiateContract(ctx, tx)
+	if err != nil {
+		return transaction.ErrInvalidDeploy(transaction.DefaultCodespace, err.Error()).Result()
+	}
+	return handleContractCallTx(ctx, am, cm, envm, sm, &transaction.ContractCallTx{
 		Address: addr,
 		Func:    transaction.ContractInitFunc,
+		Args:    tx.Args,
+		Common:  tx.Common,
+	})
+}
+
+func handleContractUpgradeTx(ctx types.Context, am account.AccountMapper, cm *contract.ContractManager, envm *contract.EnvManager, sm *db.StateManager, tx *transaction.ContractUpgradeTx) types.Result {
+	if err := cm.UpgradeContract(ctx, tx); err != nil {
+		return transaction.ErrInvalidUpgrade(transaction.DefaultCodespace, err.Error()).Result()
+	}
+	if !tx.Migrate {
+		return types.Result{}
+	}
+	return handleContractCallTx(ctx, am, cm, envm, sm, &transaction.ContractCallTx{
+		Address: tx.Address,
+		Func:    transaction.ContractMigrateFunc,
+		Args:    tx.Args,
 		Common:  tx.Common,
 	})
 }
 
-func handleContractCallTx(ctx types.Context, cm *contract.ContractManager, envm *contract.EnvManager, sm *db.StateManager, tx *transaction.ContractCallTx) types.Result {
+func handleContractCallTx(ctx types.Context, am account.AccountMapper, cm *contract.ContractManager, envm *contract.EnvManager, sm *db.StateManager, tx *transaction.ContractCallTx) types.Result {
 	env, err := envm.Get(ctx, tx.Common.From, tx.Address, contract.NewArgs(tx.Args))
 	if err != nil {
 		return transaction.ErrInvalidCall(transaction.DefaultCodespace, err.Error()).Result()
 	}
+	if tx.Amount > 0 {
+		if err := am.Transfer(ctx, tx.Common.From, tx.Amount, tx.Address); err != nil {
+			return transaction.ErrFailTransfer(transaction.DefaultCodespace, err.Error()).Result()
+		}
+		env.Value = tx.Amount
+	}
 	res, err := env.Exec(ctx, tx.Func)
-	if err != nil {
-		return transaction.ErrInvalidCall(transaction.DefaultCodespace, err.Error()).Result()
+	if rerr, ok := err.(*contract.RevertError); ok {
+		return withDebugInfo(ctx, env, types.NewError(contract.RevertCodespace, types.CodeType(rerr.Code), "%v", rerr.Error()).Result())
+	} else if lerr, ok := err.(*contract.LimitError); ok {
+		return withDebugInfo(ctx, env, transaction.ErrLimitExceeded(transaction.DefaultCodespace, lerr.Error()).Result())
+	} else if err != nil {
+		return withDebugInfo(ctx, env, transaction.ErrInvalidCall(transaction.DefaultCodespace, err.Error()).Result())
 	}
 	if err := sm.CommitState(ctx, res.State.RWSets()); err != nil {
 		return transaction.ErrInvalidCall(transaction.DefaultCodespace, err.Error()).Result()
@@ -73,11 +117,18 @@ func handleContractCallTx(ctx types.Context, cm *contract.ContractManager, envm
 	if err != nil {
 		return transaction.ErrInvalidCall(transaction.DefaultCodespace, err.Error()).Result()
 	}
-	rb, err := ContractCallTxResponse{
+	r := ContractCallTxResponse{
 		Returned:    res.Response,
 		RWSetsBytes: b,
 		Events:      res.State.Events(),
-	}.Bytes()
+	}
+	if ctx.IsSimulate() {
+		r.Debug = env.DebugInfo()
+	}
+	if ctx.IsSimulate() || envm.DeliverLogs() {
+		r.Logs = env.Logs()
+	}
+	rb, err := r.Bytes()
 	if err != nil {
 		return transaction.ErrInvalidCall(transaction.DefaultCodespace, err.Error()).Result()
 	}
@@ -95,8 +146,21 @@ type ContractCallTxResponse struct {
 	Returned    []byte
 	RWSetsBytes []byte
 	Events      []*event.Event
+	Logs        []string            // only in simulation, or if the node enables it
+	Debug       *contract.DebugInfo // only in simulation with debug mode
 }
 
 func (r ContractCallTxResponse) Bytes() ([]byte, error) {
 	return amino.MarshalBinaryBare(r)
 }
+
+// withDebugInfo sets the logs and the debug info of the failed execution to the result in simulation.
+func withDebugInfo(ctx types.Context, env *contract.Env, result types.Result) types.Result {
+	if !ctx.IsSimulate() {
+		return result
+	}
+	if b, err := (ContractCallTxResponse{Logs: env.Logs(), Debug: env.DebugInfo()}).Bytes(); err == nil {
+		result.Data = b
+	}
+	return result
+}
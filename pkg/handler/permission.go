@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/permission"
+	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// checkPermission ensures that the sender has the role which the type of tx requires.
+// Transfers and validator updates are not restricted by the roles.
+func checkPermission(ctx types.Context, pm permission.PermissionMapper, tx transaction.Transaction) types.Error {
+	from := tx.GetCommon().From
+	switch tx := tx.(type) {
+	case *transaction.ContractDeployTx, *transaction.ContractInstantiateTx, *transaction.ContractUpgradeTx:
+		if !pm.CanDeploy(ctx, from) {
+			return transaction.ErrUnauthorized(transaction.DefaultCodespace, fmt.Sprintf("%v is not a deployer", from.Hex()))
+		}
+	case *transaction.ContractCallTx:
+		if !pm.CanCall(ctx, from, tx.Address) {
+			return transaction.ErrUnauthorized(transaction.DefaultCodespace, fmt.Sprintf("%v cannot call contract %v", from.Hex(), tx.Address.Hex()))
+		}
+	case *transaction.AdminTx:
+		// the roles of a permissionless chain cannot be updated, or anyone could become an admin
+		if !pm.Enabled(ctx) || !pm.HasRole(ctx, from, permission.RoleAdmin) {
+			return transaction.ErrUnauthorized(transaction.DefaultCodespace, fmt.Sprintf("%v is not an admin", from.Hex()))
+		}
+	}
+	return nil
+}
+
+// handleAdminTx grants or revokes the roles of tx in order
+func handleAdminTx(ctx types.Context, pm permission.PermissionMapper, tx *transaction.AdminTx) types.Result {
+	for i, u := range tx.Updates {
+		role := permission.Role(u.Role)
+		if err := role.Validate(); err != nil {
+			return transaction.ErrInvalidAdminTx(transaction.DefaultCodespace, fmt.Sprintf("updates[%v]: %v", i, err)).Result()
+		}
+		if u.Contract == (common.Address{}) {
+			pm.SetRole(ctx, u.Address, role, !u.Revoke)
+			continue
+		}
+		if role != permission.RoleCaller {
+			return transaction.ErrInvalidAdminTx(transaction.DefaultCodespace, fmt.Sprintf("updates[%v]: only the caller role can be limited to a contract", i)).Result()
+		}
+		pm.SetContractCaller(ctx, u.Contract, u.Address, !u.Revoke)
+	}
+	if len(pm.Accounts(ctx, permission.RoleAdmin)) == 0 {
+		return transaction.ErrInvalidAdminTx(transaction.DefaultCodespace, "at least one admin is required").Result()
+	}
+	return types.Result{}
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/permission"
+	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+func TestPermission(t *testing.T) {
+	require := require.New(t)
+	ctx := newTestContext(t, abci.Header{ChainID: testChainID})
+	pm := permission.NewPermissionMapper(testStoreKey)
+
+	randAddr := func() common.Address {
+		return common.BytesToAddress(cmn.RandBytes(20))
+	}
+	admin, deployer, caller, other := randAddr(), randAddr(), randAddr(), randAddr()
+	contract1, contract2 := randAddr(), randAddr()
+
+	deploy := func(from common.Address) transaction.Transaction {
+		return &transaction.ContractDeployTx{Common: transaction.CommonTx{From: from}}
+	}
+	call := func(from, contract common.Address) transaction.Transaction {
+		return &transaction.ContractCallTx{Common: transaction.CommonTx{From: from}, Address: contract}
+	}
+	adminTx := func(from common.Address, updates ...transaction.RoleUpdate) *transaction.AdminTx {
+		return &transaction.AdminTx{Common: transaction.CommonTx{From: from}, Updates: updates}
+	}
+	// update returns the result of the admin tx, and discards the state of a failed tx like baseapp
+	update := func(tx *transaction.AdminTx) types.Result {
+		cctx, write := ctx.CacheContext()
+		res := handleAdminTx(cctx, pm, tx)
+		if res.IsOK() {
+			write()
+		}
+		return res
+	}
+
+	// the chain is permissionless until the permissions are enabled at the genesis
+	require.Nil(checkPermission(ctx, pm, deploy(other)))
+	require.Nil(checkPermission(ctx, pm, call(other, contract1)))
+	require.NotNil(checkPermission(ctx, pm, adminTx(other)))
+
+	require.Error(permission.ImportGenesis(ctx, pm, permission.GenesisState{Deployers: []common.Address{deployer}}))
+	require.NoError(permission.ImportGenesis(ctx, pm, permission.GenesisState{
+		Admins:    []common.Address{admin},
+		Deployers: []common.Address{deployer},
+		Callers:   []common.Address{caller},
+		ContractCallers: []permission.ContractCallers{
+			{Contract: contract2, Callers: []common.Address{other}},
+		},
+	}))
+
+	for _, cs := range []struct {
+		tx transaction.Transaction
+		ok bool
+	}{
+		{deploy(admin), true},
+		{deploy(deployer), true},
+		{deploy(caller), false},
+		{&transaction.ContractInstantiateTx{Common: transaction.CommonTx{From: caller}}, false},
+		{&transaction.ContractUpgradeTx{Common: transaction.CommonTx{From: caller}}, false},
+		{call(admin, contract1), true},
+		{call(caller, contract1), true},
+		{call(deployer, contract1), false},
+		{call(other, contract1), false},
+		// the restricted contract can be called only by its callers and the admins
+		{call(admin, contract2), true},
+		{call(other, contract2), true},
+		{call(caller, contract2), false},
+		{&transaction.TransferTx{Common: transaction.CommonTx{From: other}}, true},
+		{adminTx(admin), true},
+		{adminTx(deployer), false},
+	} {
+		err := checkPermission(ctx, pm, cs.tx)
+		if cs.ok {
+			require.Nil(err, "%T from %v", cs.tx, cs.tx.GetCommon().From.Hex())
+		} else {
+			require.NotNil(err, "%T from %v", cs.tx, cs.tx.GetCommon().From.Hex())
+			require.Equal(transaction.CodeUnauthorized, err.Code())
+		}
+	}
+
+	// an admin grants the caller role of contract2, and revokes the deployer role
+	require.True(update(adminTx(admin,
+		transaction.RoleUpdate{Address: caller, Role: uint8(permission.RoleCaller), Contract: contract2},
+		transaction.RoleUpdate{Address: deployer, Role: uint8(permission.RoleDeployer), Revoke: true},
+	)).IsOK())
+	require.Nil(checkPermission(ctx, pm, call(caller, contract2)))
+	require.NotNil(checkPermission(ctx, pm, deploy(deployer)))
+
+	// revoking the last caller of contract2 opens it to all callers
+	require.True(update(adminTx(admin,
+		transaction.RoleUpdate{Address: caller, Role: uint8(permission.RoleCaller), Contract: contract2, Revoke: true},
+		transaction.RoleUpdate{Address: other, Role: uint8(permission.RoleCaller), Contract: contract2, Revoke: true},
+	)).IsOK())
+	require.False(pm.IsRestricted(ctx, contract2))
+	require.Nil(checkPermission(ctx, pm, call(caller, contract2)))
+	require.NotNil(checkPermission(ctx, pm, call(other, contract2)))
+
+	// invalid updates
+	require.False(update(adminTx(admin, transaction.RoleUpdate{Address: other, Role: 0})).IsOK())
+	require.False(update(adminTx(admin, transaction.RoleUpdate{Address: other, Role: uint8(permission.RoleDeployer), Contract: contract1})).IsOK())
+	require.False(update(adminTx(admin, transaction.RoleUpdate{Address: admin, Role: uint8(permission.RoleAdmin), Revoke: true})).IsOK())
+
+	// the admin is handed over
+	require.True(update(adminTx(admin,
+		transaction.RoleUpdate{Address: other, Role: uint8(permission.RoleAdmin)},
+		transaction.RoleUpdate{Address: admin, Role: uint8(permission.RoleAdmin), Revoke: true},
+	)).IsOK())
+	require.NotNil(checkPermission(ctx, pm, adminTx(admin)))
+	require.Nil(checkPermission(ctx, pm, adminTx(other)))
+
+	require.Equal(&permission.GenesisState{
+		Admins:  []common.Address{other},
+		Callers: []common.Address{caller},
+	}, permission.ExportGenesis(ctx, pm))
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/bluele/hypermint/pkg/validator"
+	"github.com/ethereum/go-ethereum/common"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// handleValidatorUpdateTx updates the validator set if enough admins sign tx.
+// The updates are sent to tendermint at the end of the block.
+func handleValidatorUpdateTx(ctx types.Context, vm validator.ValidatorMapper, tx *transaction.ValidatorUpdateTx) types.Result {
+	if err := checkAdminSigners(ctx, vm, tx); err != nil {
+		return transaction.ErrInvalidValidatorUpdate(transaction.DefaultCodespace, err.Error()).Result()
+	}
+	for i, u := range tx.Updates {
+		v := validator.Validator{PubKey: u.PubKey, Power: u.Power}
+		if err := v.Validate(); err != nil {
+			return transaction.ErrInvalidValidatorUpdate(transaction.DefaultCodespace, fmt.Sprintf("updates[%v]: %v", i, err)).Result()
+		}
+		if v.Power == 0 {
+			// tendermint rejects the removal of an unknown validator
+			if _, err := vm.GetValidator(ctx, v.Address()); err != nil {
+				return transaction.ErrInvalidValidatorUpdate(transaction.DefaultCodespace, fmt.Sprintf("updates[%v]: %v", i, err)).Result()
+			}
+		}
+		vm.UpdateValidator(ctx, v)
+	}
+	vs := vm.Validators(ctx)
+	if len(vs) == 0 {
+		return transaction.ErrInvalidValidatorUpdate(transaction.DefaultCodespace, "the validator set must not be empty").Result()
+	}
+	// tendermint halts if the total power of the validator set exceeds the limit
+	if _, err := validator.TotalPower(vs); err != nil {
+		return transaction.ErrInvalidValidatorUpdate(transaction.DefaultCodespace, err.Error()).Result()
+	}
+	return types.Result{}
+}
+
+// checkAdminSigners ensures that the signers of tx are distinct admins, and they satisfy the threshold
+func checkAdminSigners(ctx types.Context, vm validator.ValidatorMapper, tx *transaction.ValidatorUpdateTx) error {
+	admins, ok := vm.GetAdmins(ctx)
+	if !ok {
+		return fmt.Errorf("no admins are configured")
+	}
+	signers, err := tx.Signers(ctx.ChainID())
+	if err != nil {
+		return err
+	}
+	seen := make(map[common.Address]bool)
+	for _, signer := range signers {
+		if !admins.Has(signer) {
+			return fmt.Errorf("signer is not an admin: %v", signer.Hex())
+		}
+		if seen[signer] {
+			return fmt.Errorf("duplicated signer: %v", signer.Hex())
+		}
+		seen[signer] = true
+	}
+	if len(seen) < int(admins.Threshold) {
+		return fmt.Errorf("not enough signers: threshold=%v signers=%v", admins.Threshold, len(seen))
+	}
+	return nil
+}
+
+// NewValidatorEndBlocker returns an EndBlocker which sends the updates of the validator set in the block to tendermint
+func NewValidatorEndBlocker(vm validator.ValidatorMapper) types.EndBlocker {
+	return func(ctx types.Context, req abci.RequestEndBlock) abci.ResponseEndBlock {
+		var res abci.ResponseEndBlock
+		for _, v := range vm.PopUpdates(ctx) {
+			res.ValidatorUpdates = append(res.ValidatorUpdates, v.ABCIValidatorUpdate())
+		}
+		return res
+	}
+}
+
+// ComposeEndBlockers returns an EndBlocker which calls the EndBlockers in order and merges their responses
+func ComposeEndBlockers(ebs ...types.EndBlocker) types.EndBlocker {
+	return func(ctx types.Context, req abci.RequestEndBlock) abci.ResponseEndBlock {
+		var res abci.ResponseEndBlock
+		for _, eb := range ebs {
+			r := eb(ctx, req)
+			res.ValidatorUpdates = append(res.ValidatorUpdates, r.ValidatorUpdates...)
+			res.Events = append(res.Events, r.Events...)
+			if r.ConsensusParamUpdates != nil {
+				res.ConsensusParamUpdates = r.ConsensusParamUpdates
+			}
+		}
+		return res
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/transaction"
+	"github.com/bluele/hypermint/pkg/validator"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+)
+
+const testChainID = "test-chain"
+
+func TestValidatorUpdate(t *testing.T) {
+	require := require.New(t)
+	ctx := newTestContext(t, abci.Header{ChainID: testChainID})
+	vm := validator.NewValidatorMapper(testStoreKey)
+
+	var admins []*ecdsa.PrivateKey
+	var addrs []common.Address
+	for i := 0; i < 3; i++ {
+		prv, err := crypto.GenerateKey()
+		require.NoError(err)
+		admins = append(admins, prv)
+		addrs = append(addrs, crypto.PubkeyToAddress(prv.PublicKey))
+	}
+	v0, err := validator.NewValidator(secp256k1.GenPrivKey().PubKey(), 10)
+	require.NoError(err)
+	vm.SetValidator(ctx, v0)
+	v1, err := validator.NewValidator(secp256k1.GenPrivKey().PubKey(), 5)
+	require.NoError(err)
+
+	// update returns the result of tx which the signers sign
+	var update = func(updates []transaction.ValidatorUpdate, signers ...*ecdsa.PrivateKey) types.Result {
+		tx := &transaction.ValidatorUpdateTx{
+			Common: transaction.CommonTx{
+				Code: transaction.VALIDATOR_UPDATE,
+				From: crypto.PubkeyToAddress(signers[0].PublicKey),
+				Gas:  1,
+			},
+			Updates: updates,
+		}
+		for _, prv := range signers[1:] {
+			sig, err := crypto.Sign(tx.GetSignBytes(testChainID), prv)
+			require.NoError(err)
+			tx.AddSignature(sig)
+		}
+		sig, err := crypto.Sign(tx.GetSignBytes(testChainID), signers[0])
+		require.NoError(err)
+		tx.SetSignature(sig)
+		require.Nil(tx.VerifySignature(testChainID))
+
+		// the state of a failed tx is discarded like baseapp
+		cctx, write := ctx.CacheContext()
+		res := handleValidatorUpdateTx(cctx, vm, tx)
+		if res.IsOK() {
+			write()
+		}
+		return res
+	}
+	add := []transaction.ValidatorUpdate{{PubKey: v1.PubKey, Power: v1.Power}}
+
+	// no admins are configured
+	require.False(update(add, admins[0]).IsOK())
+
+	require.Error(vm.SetAdmins(ctx, validator.Admins{Addresses: addrs, Threshold: 4}))
+	require.Error(vm.SetAdmins(ctx, validator.Admins{Addresses: []common.Address{addrs[0], addrs[0]}, Threshold: 1}))
+	require.NoError(vm.SetAdmins(ctx, validator.Admins{Addresses: addrs, Threshold: 2}))
+
+	// the signers must satisfy the threshold, and they must be distinct admins
+	require.False(update(add, admins[0]).IsOK())
+	require.False(update(add, admins[0], admins[0]).IsOK())
+	other, err := crypto.GenerateKey()
+	require.NoError(err)
+	require.False(update(add, admins[0], other).IsOK())
+	require.False(update([]transaction.ValidatorUpdate{{PubKey: []byte("invalid"), Power: 1}}, admins[0], admins[1]).IsOK())
+	require.Empty(NewValidatorEndBlocker(vm)(ctx, abci.RequestEndBlock{}).ValidatorUpdates)
+
+	require.True(update(add, admins[0], admins[2]).IsOK())
+	require.Len(vm.Validators(ctx), 2)
+
+	// re-weight and remove validators in a block
+	require.True(update([]transaction.ValidatorUpdate{{PubKey: v0.PubKey, Power: 20}}, admins[1], admins[2]).IsOK())
+	require.True(update([]transaction.ValidatorUpdate{{PubKey: v1.PubKey}}, admins[1], admins[2], admins[0]).IsOK())
+	v, err := vm.GetValidator(ctx, v0.Address())
+	require.NoError(err)
+	require.EqualValues(20, v.Power)
+	_, err = vm.GetValidator(ctx, v1.Address())
+	require.Equal(validator.ErrValidatorNotFound, err)
+
+	// the power of each validator and the total power of the validator set are bounded
+	require.False(update([]transaction.ValidatorUpdate{{PubKey: v0.PubKey, Power: validator.MaxPower + 1}}, admins[0], admins[1]).IsOK())
+	require.True(update([]transaction.ValidatorUpdate{{PubKey: v0.PubKey, Power: validator.MaxPower}}, admins[0], admins[1]).IsOK())
+	require.False(update([]transaction.ValidatorUpdate{{PubKey: v1.PubKey, Power: 1}}, admins[0], admins[1]).IsOK())
+	require.True(update([]transaction.ValidatorUpdate{{PubKey: v0.PubKey, Power: 20}}, admins[0], admins[1]).IsOK())
+
+	// the validator set must not be empty, and unknown validators cannot be removed
+	require.False(update([]transaction.ValidatorUpdate{{PubKey: v0.PubKey}}, admins[0], admins[1]).IsOK())
+	require.False(update([]transaction.ValidatorUpdate{{PubKey: v1.PubKey}}, admins[0], admins[1]).IsOK())
+
+	// the EndBlocker sends the last update of each validator in the block
+	eb := ComposeEndBlockers(func(types.Context, abci.RequestEndBlock) abci.ResponseEndBlock {
+		return abci.ResponseEndBlock{}
+	}, NewValidatorEndBlocker(vm))
+	updates := eb(ctx, abci.RequestEndBlock{}).ValidatorUpdates
+	expected := []abci.ValidatorUpdate{
+		validator.Validator{PubKey: v0.PubKey, Power: 20}.ABCIValidatorUpdate(),
+		validator.Validator{PubKey: v1.PubKey}.ABCIValidatorUpdate(),
+	}
+	require.ElementsMatch(expected, updates)
+	require.Empty(NewValidatorEndBlocker(vm)(ctx, abci.RequestEndBlock{}).ValidatorUpdates)
+}
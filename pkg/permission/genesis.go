@@ -0,0 +1,63 @@
+package permission
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GenesisState is the roles which the chain starts with. The permissions are enabled if it is given.
+type GenesisState struct {
+	Admins    []common.Address `json:"admins"`
+	Deployers []common.Address `json:"deployers,omitempty"`
+	Callers   []common.Address `json:"callers,omitempty"`
+	// ContractCallers restrict the calls of the contracts to their callers
+	ContractCallers []ContractCallers `json:"contract_callers,omitempty"`
+}
+
+// Validate returns an error if the state has no admin
+func (gs GenesisState) Validate() error {
+	if len(gs.Admins) == 0 {
+		return errors.New("at least one admin is required")
+	}
+	for _, cc := range gs.ContractCallers {
+		if len(cc.Callers) == 0 {
+			return fmt.Errorf("contract %v has no callers", cc.Contract.Hex())
+		}
+	}
+	return nil
+}
+
+// ImportGenesis enables the permissions, and grants the roles of the genesis state
+func ImportGenesis(ctx types.Context, pm PermissionMapper, gs GenesisState) error {
+	if err := gs.Validate(); err != nil {
+		return err
+	}
+	pm.Enable(ctx)
+	for i, addrs := range [][]common.Address{gs.Admins, gs.Deployers, gs.Callers} {
+		for _, addr := range addrs {
+			pm.SetRole(ctx, addr, Roles[i], true)
+		}
+	}
+	for _, cc := range gs.ContractCallers {
+		for _, addr := range cc.Callers {
+			pm.SetContractCaller(ctx, cc.Contract, addr, true)
+		}
+	}
+	return nil
+}
+
+// ExportGenesis returns the roles of the chain. If the permissions are not enabled, it returns nil.
+func ExportGenesis(ctx types.Context, pm PermissionMapper) *GenesisState {
+	if !pm.Enabled(ctx) {
+		return nil
+	}
+	return &GenesisState{
+		Admins:          pm.Accounts(ctx, RoleAdmin),
+		Deployers:       pm.Accounts(ctx, RoleDeployer),
+		Callers:         pm.Accounts(ctx, RoleCaller),
+		ContractCallers: pm.ContractCallers(ctx),
+	}
+}
@@ -0,0 +1,209 @@
+package permission
+
+import (
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	enabledKey     = []byte("enabled")
+	rolePrefix     = []byte("role/")
+	contractPrefix = []byte("contract/")
+	grantedValue   = []byte{1}
+)
+
+// Role is a permission which is granted to accounts
+type Role uint8
+
+const (
+	// RoleAdmin can send any tx, and can update the roles by AdminTx
+	RoleAdmin Role = 1 + iota
+	// RoleDeployer can deploy, instantiate and upgrade contracts
+	RoleDeployer
+	// RoleCaller can call contracts which are not restricted to their own callers
+	RoleCaller
+)
+
+// Roles are all the roles
+var Roles = []Role{RoleAdmin, RoleDeployer, RoleCaller}
+
+// ParseRole returns the role of the name
+func ParseRole(s string) (Role, error) {
+	for _, r := range Roles {
+		if r.String() == s {
+			return r, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown role: %v", s)
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleAdmin:
+		return "admin"
+	case RoleDeployer:
+		return "deployer"
+	case RoleCaller:
+		return "caller"
+	default:
+		return fmt.Sprintf("Role(%d)", uint8(r))
+	}
+}
+
+// Validate returns an error if the role is unknown
+func (r Role) Validate() error {
+	if r < RoleAdmin || r > RoleCaller {
+		return fmt.Errorf("unknown role: %d", uint8(r))
+	}
+	return nil
+}
+
+func (r Role) MarshalText() ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(r.String()), nil
+}
+
+func (r *Role) UnmarshalText(b []byte) error {
+	role, err := ParseRole(string(b))
+	if err != nil {
+		return err
+	}
+	*r = role
+	return nil
+}
+
+// PermissionMapper is a store of the roles of accounts.
+// Until the permissions are enabled at the genesis, the chain is permissionless.
+type PermissionMapper interface {
+	Enabled(ctx types.Context) bool
+	Enable(ctx types.Context)
+	HasRole(ctx types.Context, addr common.Address, role Role) bool
+	// SetRole grants the role to the account, or revokes it if granted is false
+	SetRole(ctx types.Context, addr common.Address, role Role, granted bool)
+	// Accounts returns the accounts which have the role in the order of their addresses
+	Accounts(ctx types.Context, role Role) []common.Address
+	// IsRestricted returns true if the contract can be called only by its own callers
+	IsRestricted(ctx types.Context, contract common.Address) bool
+	IsContractCaller(ctx types.Context, contract common.Address, addr common.Address) bool
+	// SetContractCaller allows the account to call the contract, or disallows it if granted is false.
+	// The contract is restricted while it has any callers.
+	SetContractCaller(ctx types.Context, contract common.Address, addr common.Address, granted bool)
+	// ContractCallers returns the callers of each restricted contract in the order of the addresses
+	ContractCallers(ctx types.Context) []ContractCallers
+	// CanDeploy returns true if the account can deploy, instantiate or upgrade contracts
+	CanDeploy(ctx types.Context, addr common.Address) bool
+	// CanCall returns true if the account can call the contract
+	CanCall(ctx types.Context, addr common.Address, contract common.Address) bool
+}
+
+type permissionMapper struct {
+	storeKey types.StoreKey
+}
+
+func NewPermissionMapper(storeKey types.StoreKey) PermissionMapper {
+	return &permissionMapper{
+		storeKey: storeKey,
+	}
+}
+
+func (pm *permissionMapper) Enabled(ctx types.Context) bool {
+	return ctx.KVStore(pm.storeKey).Has(enabledKey)
+}
+
+func (pm *permissionMapper) Enable(ctx types.Context) {
+	ctx.KVStore(pm.storeKey).Set(enabledKey, grantedValue)
+}
+
+func (pm *permissionMapper) HasRole(ctx types.Context, addr common.Address, role Role) bool {
+	return pm.getRoleStore(ctx, role).Has(addr.Bytes())
+}
+
+func (pm *permissionMapper) SetRole(ctx types.Context, addr common.Address, role Role, granted bool) {
+	kvs := pm.getRoleStore(ctx, role)
+	if granted {
+		kvs.Set(addr.Bytes(), grantedValue)
+	} else {
+		kvs.Delete(addr.Bytes())
+	}
+}
+
+func (pm *permissionMapper) Accounts(ctx types.Context, role Role) []common.Address {
+	var addrs []common.Address
+	it := pm.getRoleStore(ctx, role).Iterator(nil, nil)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		addrs = append(addrs, common.BytesToAddress(it.Key()))
+	}
+	return addrs
+}
+
+func (pm *permissionMapper) IsRestricted(ctx types.Context, contract common.Address) bool {
+	it := pm.getContractStore(ctx, contract).Iterator(nil, nil)
+	defer it.Close()
+	return it.Valid()
+}
+
+func (pm *permissionMapper) IsContractCaller(ctx types.Context, contract common.Address, addr common.Address) bool {
+	return pm.getContractStore(ctx, contract).Has(addr.Bytes())
+}
+
+func (pm *permissionMapper) SetContractCaller(ctx types.Context, contract common.Address, addr common.Address, granted bool) {
+	kvs := pm.getContractStore(ctx, contract)
+	if granted {
+		kvs.Set(addr.Bytes(), grantedValue)
+	} else {
+		kvs.Delete(addr.Bytes())
+	}
+}
+
+func (pm *permissionMapper) ContractCallers(ctx types.Context) []ContractCallers {
+	var ccs []ContractCallers
+	it := ctx.KVStore(pm.storeKey).Prefix(contractPrefix).Iterator(nil, nil)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		key := it.Key()
+		contract := common.BytesToAddress(key[:common.AddressLength])
+		caller := common.BytesToAddress(key[common.AddressLength:])
+		if n := len(ccs); n > 0 && ccs[n-1].Contract == contract {
+			ccs[n-1].Callers = append(ccs[n-1].Callers, caller)
+			continue
+		}
+		ccs = append(ccs, ContractCallers{Contract: contract, Callers: []common.Address{caller}})
+	}
+	return ccs
+}
+
+func (pm *permissionMapper) CanDeploy(ctx types.Context, addr common.Address) bool {
+	if !pm.Enabled(ctx) {
+		return true
+	}
+	return pm.HasRole(ctx, addr, RoleAdmin) || pm.HasRole(ctx, addr, RoleDeployer)
+}
+
+func (pm *permissionMapper) CanCall(ctx types.Context, addr common.Address, contract common.Address) bool {
+	if !pm.Enabled(ctx) || pm.HasRole(ctx, addr, RoleAdmin) {
+		return true
+	}
+	if pm.IsRestricted(ctx, contract) {
+		return pm.IsContractCaller(ctx, contract, addr)
+	}
+	return pm.HasRole(ctx, addr, RoleCaller)
+}
+
+func (pm *permissionMapper) getRoleStore(ctx types.Context, role Role) types.KVStore {
+	return ctx.KVStore(pm.storeKey).Prefix(append(append([]byte{}, rolePrefix...), byte(role)))
+}
+
+func (pm *permissionMapper) getContractStore(ctx types.Context, contract common.Address) types.KVStore {
+	return ctx.KVStore(pm.storeKey).Prefix(append(append([]byte{}, contractPrefix...), contract.Bytes()...))
+}
+
+// ContractCallers are the only accounts which can call the contract, except the admins
+type ContractCallers struct {
+	Contract common.Address   `json:"contract"`
+	Callers  []common.Address `json:"callers"`
+}
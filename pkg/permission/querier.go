@@ -0,0 +1,81 @@
+package permission
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+const (
+	QuerierRoute = "permission"
+
+	// QueryRoles returns the roles of the address as AccountRoles
+	QueryRoles = "roles"
+	// QueryState returns all the roles as GenesisState
+	QueryState = "state"
+)
+
+// AccountRoles are the roles of an account
+type AccountRoles struct {
+	// Enabled is false if the chain is permissionless
+	Enabled bool           `json:"enabled"`
+	Address common.Address `json:"address"`
+	Roles   []Role         `json:"roles"`
+	// Contracts are the restricted contracts which the account can call
+	Contracts []common.Address `json:"contracts,omitempty"`
+}
+
+// NewQuerier returns a querier for the roles
+func NewQuerier(pm PermissionMapper) types.Querier {
+	return func(ctx types.Context, path []string, req abci.RequestQuery) ([]byte, types.Error) {
+		if len(path) == 0 {
+			return nil, types.ErrUnknownRequest("no query path provided")
+		}
+		switch path[0] {
+		case QueryRoles:
+			return queryRoles(ctx, pm, req)
+		case QueryState:
+			return queryState(ctx, pm)
+		default:
+			return nil, types.ErrUnknownRequest(fmt.Sprintf("unknown permission query endpoint: %v", path[0]))
+		}
+	}
+}
+
+func queryRoles(ctx types.Context, pm PermissionMapper, req abci.RequestQuery) ([]byte, types.Error) {
+	if len(req.Data) != common.AddressLength {
+		return nil, types.ErrUnknownRequest(fmt.Sprintf("invalid address length: %v", len(req.Data)))
+	}
+	addr := common.BytesToAddress(req.Data)
+	ar := AccountRoles{
+		Enabled: pm.Enabled(ctx),
+		Address: addr,
+		Roles:   []Role{},
+	}
+	for _, role := range Roles {
+		if pm.HasRole(ctx, addr, role) {
+			ar.Roles = append(ar.Roles, role)
+		}
+	}
+	for _, cc := range pm.ContractCallers(ctx) {
+		if pm.IsContractCaller(ctx, cc.Contract, addr) {
+			ar.Contracts = append(ar.Contracts, cc.Contract)
+		}
+	}
+	return marshalJSON(ar)
+}
+
+func queryState(ctx types.Context, pm PermissionMapper) ([]byte, types.Error) {
+	return marshalJSON(ExportGenesis(ctx, pm))
+}
+
+func marshalJSON(v interface{}) ([]byte, types.Error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, types.ErrInternal(err.Error())
+	}
+	return b, nil
+}
@@ -6,10 +6,12 @@ import (
 	"github.com/tendermint/tm-db"
 )
 
-func GetTestCommitMultiStore(key types.StoreKey) (types.CommitMultiStore, error) {
+func GetTestCommitMultiStore(keys ...types.StoreKey) (types.CommitMultiStore, error) {
 	memdb := db.NewMemDB()
 	cms := store.NewCommitMultiStore(memdb)
-	cms.MountStoreWithDB(key, types.StoreTypeIAVL, nil)
+	for _, key := range keys {
+		cms.MountStoreWithDB(key, types.StoreTypeIAVL, nil)
+	}
 	if err := cms.LoadLatestVersion(); err != nil {
 		return nil, err
 	}
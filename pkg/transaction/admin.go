@@ -0,0 +1,72 @@
+package transaction
+
+import (
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// RoleUpdate grants a role to an account, or revokes it
+type RoleUpdate struct {
+	Address common.Address
+	Role    uint8
+	// Contract limits the caller role to the contract. If it is empty, the role is not limited.
+	Contract common.Address
+	Revoke   bool
+}
+
+// AdminTx updates the roles of accounts. The sender must be an admin.
+type AdminTx struct {
+	Common  CommonTx
+	Updates []RoleUpdate
+}
+
+func DecodeAdminTx(b []byte) (*AdminTx, error) {
+	tx := new(AdminTx)
+	return tx, rlp.DecodeBytes(b, tx)
+}
+
+func (tx *AdminTx) GetCommon() CommonTx {
+	return tx.Common
+}
+
+func (tx *AdminTx) SetSignature(sig []byte) {
+	tx.Common.SetSignature(sig)
+}
+
+func (tx *AdminTx) Decode(b []byte) error {
+	return rlp.DecodeBytes(b, tx)
+}
+
+func (tx *AdminTx) ValidateBasic() types.Error {
+	if err := tx.Common.ValidateBasic(); err != nil {
+		return err
+	}
+	if len(tx.Updates) == 0 {
+		return ErrInvalidAdminTx(DefaultCodespace, "len(tx.Updates) == 0")
+	}
+	for _, u := range tx.Updates {
+		if isEmptyAddr(u.Address) {
+			return ErrInvalidAdminTx(DefaultCodespace, "tx.Updates[i].Address == empty")
+		}
+	}
+	return nil
+}
+
+func (tx *AdminTx) VerifySignature(chainID string) types.Error {
+	return tx.Common.VerifySignature(tx.GetSignBytes(chainID))
+}
+
+func (tx *AdminTx) GetSignBytes(chainID string) []byte {
+	ntx := *tx
+	ntx.SetSignature(nil)
+	return SignBytes(chainID, ntx.Bytes())
+}
+
+func (tx *AdminTx) Bytes() []byte {
+	b, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
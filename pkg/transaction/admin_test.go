@@ -0,0 +1,68 @@
+package transaction
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+func TestAdminTxEncoding(t *testing.T) {
+	var cases = []struct {
+		tx          *AdminTx
+		decodeError bool
+	}{
+		{
+			&AdminTx{
+				Updates: []RoleUpdate{
+					{Address: common.BytesToAddress(cmn.RandBytes(20)), Role: 2},
+					{Address: common.BytesToAddress(cmn.RandBytes(20)), Role: 3, Contract: common.BytesToAddress(cmn.RandBytes(20)), Revoke: true},
+				},
+				Common: CommonTx{
+					Code:      ADMIN,
+					From:      common.BytesToAddress(cmn.RandBytes(20)),
+					Nonce:     1,
+					Gas:       1,
+					Signature: cmn.RandBytes(65),
+				},
+			},
+			false,
+		},
+		{
+			&AdminTx{
+				Updates: []RoleUpdate{{Address: common.BytesToAddress(cmn.RandBytes(20)), Role: 1}},
+				Common: CommonTx{
+					Code:      0,
+					From:      common.BytesToAddress(cmn.RandBytes(20)),
+					Nonce:     1,
+					Gas:       1,
+					Signature: cmn.RandBytes(65),
+				},
+			},
+			true,
+		},
+	}
+
+	for i, cs := range cases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			assert := assert.New(t)
+			b := cs.tx.Bytes()
+			tx1, err := DecodeAdminTx(b)
+			assert.NoError(err)
+			assert.Equal(cs.tx, tx1)
+			assert.Nil(tx1.ValidateBasic())
+
+			tx2, err := DecodeTx(b)
+			if cs.decodeError {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			tx3, ok := tx2.(*AdminTx)
+			assert.True(ok)
+			assert.NotNil(tx3)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/bluele/hypermint/pkg/abci/types"
 	perrors "github.com/bluele/hypermint/pkg/errors"
+	"github.com/bluele/hypermint/pkg/util"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -17,6 +18,7 @@ type CommonTx struct {
 	From      common.Address
 	Nonce     uint64
 	Gas       uint64
+	GasPrice  uint64
 	Signature []byte
 }
 
@@ -24,36 +26,61 @@ func (tx *CommonTx) ValidateBasic() types.Error {
 	if isEmptyAddr(tx.From) {
 		return ErrInvalidTx(DefaultCodespace, "tx.From == nil")
 	}
-	// TODO Currently we don't support a gas system.
-	// if tx.Gas == 0 {
-	// 	return ErrInvalidTx(DefaultCodespace, "tx.Gas == 0")
-	// }
+	if tx.Gas == 0 {
+		return ErrInvalidTx(DefaultCodespace, "tx.Gas == 0")
+	}
+	if _, overflow := types.MulUint64Overflow(tx.Gas, tx.GasPrice); overflow {
+		return ErrInvalidTx(DefaultCodespace, "tx.Gas * tx.GasPrice overflows")
+	}
 	if len(tx.Signature) == 0 {
 		return ErrInvalidTx(DefaultCodespace, "len(tx.Signature) == 0")
 	}
 	return nil
 }
 
+// Fee returns the max fee that sender pays for the tx
+func (tx CommonTx) Fee() uint64 {
+	return tx.Gas * tx.GasPrice
+}
+
+// SignBytes returns a hash to be signed, which binds txBytes to given chain ID.
+// This prevents a tx signed for a chain from being replayed on other chains.
+func SignBytes(chainID string, txBytes []byte) []byte {
+	b, err := rlp.EncodeToBytes([]interface{}{chainID, txBytes})
+	if err != nil {
+		panic(err)
+	}
+	return util.TxHash(b)
+}
+
 func (tx *CommonTx) SetSignature(sig []byte) {
 	tx.Signature = sig
 }
 
 func (tx *CommonTx) verifySignature(hash []byte) error {
-	rawPub, err := crypto.Ecrecover(hash, tx.Signature)
-	if err != nil {
-		return errors.Wrap(err, "crypto.Ecrecover")
-	}
-	pub, err := crypto.UnmarshalPubkey(rawPub)
+	signer, err := recoverSigner(hash, tx.Signature)
 	if err != nil {
-		return errors.Wrap(err, "crypto.DecompressPubkey")
+		return err
 	}
-	signer := crypto.PubkeyToAddress(*pub)
 	if signer != tx.From {
 		return fmt.Errorf("signer mismatch: %v != %v", signer.Hex(), tx.From.Hex())
 	}
 	return nil
 }
 
+// recoverSigner returns the address which signed the hash
+func recoverSigner(hash, sig []byte) (common.Address, error) {
+	rawPub, err := crypto.Ecrecover(hash, sig)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "crypto.Ecrecover")
+	}
+	pub, err := crypto.UnmarshalPubkey(rawPub)
+	if err != nil {
+		return common.Address{}, errors.Wrap(err, "crypto.DecompressPubkey")
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
 func (tx *CommonTx) VerifySignature(hash []byte) types.Error {
 	err := tx.verifySignature(hash)
 	if err == nil {
@@ -85,6 +112,9 @@ func (tx CommonTx) EncodeNoSig(w io.Writer) error {
 	if err := rlp.Encode(w, tx.Gas); err != nil {
 		return err
 	}
+	if err := rlp.Encode(w, tx.GasPrice); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -101,6 +131,9 @@ func (tx *CommonTx) DecodeRLP(s *rlp.Stream) error {
 	if err := s.Decode(&tx.Gas); err != nil {
 		return err
 	}
+	if err := s.Decode(&tx.GasPrice); err != nil {
+		return err
+	}
 	b, err := s.Bytes()
 	if err != nil {
 		return err
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/bluele/hypermint/pkg/abci/types"
-	"github.com/bluele/hypermint/pkg/util"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -16,9 +15,10 @@ const (
 type ContractCallTx struct {
 	Common     CommonTx
 	Address    common.Address
+	Amount     uint64 // native coin which is sent to the contract
 	Func       string // function name
 	Args       [][]byte
-	RWSetsHash []byte
+	RWSetsHash []byte // expected hash of the RWSets of contract states, which doesn't cover balances
 }
 
 func DecodeContractCallTx(b []byte) (*ContractCallTx, error) {
@@ -26,6 +26,10 @@ func DecodeContractCallTx(b []byte) (*ContractCallTx, error) {
 	return tx, rlp.DecodeBytes(b, tx)
 }
 
+func (tx *ContractCallTx) GetCommon() CommonTx {
+	return tx.Common
+}
+
 func (tx *ContractCallTx) SetSignature(sig []byte) {
 	tx.Common.SetSignature(sig)
 }
@@ -37,17 +41,24 @@ func (tx *ContractCallTx) ValidateBasic() types.Error {
 	if tx.Func == ContractInitFunc {
 		return ErrInvalidCall(DefaultCodespace, fmt.Sprintf("func '%v' is reserved by contract initializer", ContractInitFunc))
 	}
-	return tx.Common.VerifySignature(tx.GetSignBytes())
+	if tx.Func == ContractMigrateFunc {
+		return ErrInvalidCall(DefaultCodespace, fmt.Sprintf("func '%v' is reserved by contract upgrade", ContractMigrateFunc))
+	}
+	return nil
 }
 
 func (tx *ContractCallTx) Decode(b []byte) error {
 	return rlp.DecodeBytes(b, tx)
 }
 
-func (tx *ContractCallTx) GetSignBytes() []byte {
+func (tx *ContractCallTx) VerifySignature(chainID string) types.Error {
+	return tx.Common.VerifySignature(tx.GetSignBytes(chainID))
+}
+
+func (tx *ContractCallTx) GetSignBytes(chainID string) []byte {
 	ntx := *tx
 	ntx.SetSignature(nil)
-	return util.TxHash(ntx.Bytes())
+	return SignBytes(chainID, ntx.Bytes())
 }
 
 func (tx *ContractCallTx) Bytes() []byte {
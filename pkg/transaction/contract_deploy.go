@@ -2,10 +2,11 @@ package transaction
 
 import (
 	"github.com/bluele/hypermint/pkg/abci/types"
-	"github.com/bluele/hypermint/pkg/util"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
+// ContractDeployTx uploads the code into the code store.
+// ContractInstantiateTx creates a contract instance of the uploaded code.
 type ContractDeployTx struct {
 	Common CommonTx
 	Code   []byte
@@ -16,6 +17,10 @@ func DecodeContractDeployTx(b []byte) (*ContractDeployTx, error) {
 	return tx, rlp.DecodeBytes(b, tx)
 }
 
+func (tx *ContractDeployTx) GetCommon() CommonTx {
+	return tx.Common
+}
+
 func (tx *ContractDeployTx) SetSignature(sig []byte) {
 	tx.Common.SetSignature(sig)
 }
@@ -31,13 +36,17 @@ func (tx *ContractDeployTx) ValidateBasic() types.Error {
 	if tx.Code == nil {
 		return ErrInvalidDeploy(DefaultCodespace, "tx.Code == nil")
 	}
-	return tx.Common.VerifySignature(tx.GetSignBytes())
+	return nil
+}
+
+func (tx *ContractDeployTx) VerifySignature(chainID string) types.Error {
+	return tx.Common.VerifySignature(tx.GetSignBytes(chainID))
 }
 
-func (tx *ContractDeployTx) GetSignBytes() []byte {
+func (tx *ContractDeployTx) GetSignBytes(chainID string) []byte {
 	ntx := *tx
 	ntx.SetSignature(nil)
-	return util.TxHash(ntx.Bytes())
+	return SignBytes(chainID, ntx.Bytes())
 }
 
 func (tx *ContractDeployTx) Bytes() []byte {
@@ -0,0 +1,59 @@
+package transaction
+
+import (
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ContractInstantiateTx creates a new contract instance of the uploaded code, and calls its init function.
+type ContractInstantiateTx struct {
+	Common   CommonTx
+	CodeHash common.Hash
+	Args     [][]byte // arguments for init function
+}
+
+func DecodeContractInstantiateTx(b []byte) (*ContractInstantiateTx, error) {
+	tx := new(ContractInstantiateTx)
+	return tx, rlp.DecodeBytes(b, tx)
+}
+
+func (tx *ContractInstantiateTx) GetCommon() CommonTx {
+	return tx.Common
+}
+
+func (tx *ContractInstantiateTx) SetSignature(sig []byte) {
+	tx.Common.SetSignature(sig)
+}
+
+func (tx *ContractInstantiateTx) Decode(b []byte) error {
+	return rlp.DecodeBytes(b, tx)
+}
+
+func (tx *ContractInstantiateTx) ValidateBasic() types.Error {
+	if err := tx.Common.ValidateBasic(); err != nil {
+		return err
+	}
+	if tx.CodeHash == (common.Hash{}) {
+		return ErrInvalidDeploy(DefaultCodespace, "tx.CodeHash == empty")
+	}
+	return nil
+}
+
+func (tx *ContractInstantiateTx) VerifySignature(chainID string) types.Error {
+	return tx.Common.VerifySignature(tx.GetSignBytes(chainID))
+}
+
+func (tx *ContractInstantiateTx) GetSignBytes(chainID string) []byte {
+	ntx := *tx
+	ntx.SetSignature(nil)
+	return SignBytes(chainID, ntx.Bytes())
+}
+
+func (tx *ContractInstantiateTx) Bytes() []byte {
+	b, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
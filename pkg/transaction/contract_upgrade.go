@@ -0,0 +1,68 @@
+package transaction
+
+import (
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	ContractMigrateFunc = "migrate"
+)
+
+// ContractUpgradeTx replaces the code of the contract, and keeps its state.
+type ContractUpgradeTx struct {
+	Common  CommonTx
+	Address common.Address
+	Code    []byte
+	Migrate bool     // if true, migrate function of the new code is called after upgrade
+	Args    [][]byte // arguments for migrate function
+}
+
+func DecodeContractUpgradeTx(b []byte) (*ContractUpgradeTx, error) {
+	tx := new(ContractUpgradeTx)
+	return tx, rlp.DecodeBytes(b, tx)
+}
+
+func (tx *ContractUpgradeTx) GetCommon() CommonTx {
+	return tx.Common
+}
+
+func (tx *ContractUpgradeTx) SetSignature(sig []byte) {
+	tx.Common.SetSignature(sig)
+}
+
+func (tx *ContractUpgradeTx) Decode(b []byte) error {
+	return rlp.DecodeBytes(b, tx)
+}
+
+func (tx *ContractUpgradeTx) ValidateBasic() types.Error {
+	if err := tx.Common.ValidateBasic(); err != nil {
+		return err
+	}
+	if isEmptyAddr(tx.Address) {
+		return ErrInvalidUpgrade(DefaultCodespace, "tx.Address == empty")
+	}
+	if tx.Code == nil {
+		return ErrInvalidUpgrade(DefaultCodespace, "tx.Code == nil")
+	}
+	return nil
+}
+
+func (tx *ContractUpgradeTx) VerifySignature(chainID string) types.Error {
+	return tx.Common.VerifySignature(tx.GetSignBytes(chainID))
+}
+
+func (tx *ContractUpgradeTx) GetSignBytes(chainID string) []byte {
+	ntx := *tx
+	ntx.SetSignature(nil)
+	return SignBytes(chainID, ntx.Bytes())
+}
+
+func (tx *ContractUpgradeTx) Bytes() []byte {
+	b, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
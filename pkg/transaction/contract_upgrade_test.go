@@ -0,0 +1,69 @@
+package transaction
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+func TestContractUpgradeTxEncoding(t *testing.T) {
+	var cases = []struct {
+		tx          *ContractUpgradeTx
+		decodeError bool
+	}{
+		{
+			&ContractUpgradeTx{
+				Address: common.BytesToAddress(cmn.RandBytes(20)),
+				Code:    cmn.RandBytes(1024),
+				Migrate: true,
+				Args:    [][]byte{cmn.RandBytes(8)},
+				Common: CommonTx{
+					Code:      CONTRACT_UPGRADE,
+					From:      common.BytesToAddress(cmn.RandBytes(20)),
+					Nonce:     1,
+					Gas:       1,
+					Signature: cmn.RandBytes(65),
+				},
+			},
+			false,
+		},
+		{
+			&ContractUpgradeTx{
+				Address: common.BytesToAddress(cmn.RandBytes(20)),
+				Code:    cmn.RandBytes(1024),
+				Args:    [][]byte{},
+				Common: CommonTx{
+					Code:      0,
+					From:      common.BytesToAddress(cmn.RandBytes(20)),
+					Nonce:     1,
+					Gas:       1,
+					Signature: cmn.RandBytes(65),
+				},
+			},
+			true,
+		},
+	}
+
+	for i, cs := range cases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			assert := assert.New(t)
+			b := cs.tx.Bytes()
+			tx1, err := DecodeContractUpgradeTx(b)
+			assert.NoError(err)
+			assert.Equal(cs.tx, tx1)
+
+			tx2, err := DecodeTx(b)
+			if cs.decodeError {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			tx3, ok := tx2.(*ContractUpgradeTx)
+			assert.True(ok)
+			assert.NotNil(tx3)
+		})
+	}
+}
@@ -13,6 +13,13 @@ const (
 	CodeFailTransfer    types.CodeType = 103
 	CodeInvalidDeploy   types.CodeType = 104
 	CodeInvalidCall     types.CodeType = 105
+	CodeInvalidNonce    types.CodeType = 106
+	CodeInvalidUpgrade  types.CodeType = 107
+	CodeLimitExceeded   types.CodeType = 108
+
+	CodeInvalidValidatorUpdate types.CodeType = 109
+	CodeInvalidAdminTx         types.CodeType = 110
+	CodeUnauthorized           types.CodeType = 111
 )
 
 // NOTE: Don't stringer this, we'll put better messages in later.
@@ -20,6 +27,16 @@ func codeToDefaultMsg(code types.CodeType) string {
 	switch code {
 	case CodeInvalidTransfer:
 		return "invalid transfer"
+	case CodeInvalidNonce:
+		return "invalid nonce"
+	case CodeLimitExceeded:
+		return "limit exceeded"
+	case CodeInvalidValidatorUpdate:
+		return "invalid validator update"
+	case CodeInvalidAdminTx:
+		return "invalid admin tx"
+	case CodeUnauthorized:
+		return "unauthorized"
 	default:
 		return types.CodeToDefaultMsg(code)
 	}
@@ -48,6 +65,30 @@ func ErrInvalidCall(codespace types.CodespaceType, msg string) types.Error {
 	return newError(codespace, CodeInvalidCall, msg)
 }
 
+func ErrInvalidUpgrade(codespace types.CodespaceType, msg string) types.Error {
+	return newError(codespace, CodeInvalidUpgrade, msg)
+}
+
+func ErrInvalidNonce(codespace types.CodespaceType, msg string) types.Error {
+	return newError(codespace, CodeInvalidNonce, msg)
+}
+
+func ErrLimitExceeded(codespace types.CodespaceType, msg string) types.Error {
+	return newError(codespace, CodeLimitExceeded, msg)
+}
+
+func ErrInvalidValidatorUpdate(codespace types.CodespaceType, msg string) types.Error {
+	return newError(codespace, CodeInvalidValidatorUpdate, msg)
+}
+
+func ErrInvalidAdminTx(codespace types.CodespaceType, msg string) types.Error {
+	return newError(codespace, CodeInvalidAdminTx, msg)
+}
+
+func ErrUnauthorized(codespace types.CodespaceType, msg string) types.Error {
+	return newError(codespace, CodeUnauthorized, msg)
+}
+
 //----------------------------------------
 
 func msgOrDefaultMsg(msg string, code types.CodeType) string {
@@ -14,11 +14,17 @@ const (
 	TRANSFER uint8 = 1 + iota
 	CONTRACT_DEPLOY
 	CONTRACT_CALL
+	CONTRACT_UPGRADE
+	CONTRACT_INSTANTIATE
+	VALIDATOR_UPDATE
+	ADMIN
 )
 
 type Transaction interface {
 	types.Tx
-	GetSignBytes() []byte
+	GetCommon() CommonTx
+	GetSignBytes(chainID string) []byte
+	VerifySignature(chainID string) types.Error
 	SetSignature([]byte)
 	Bytes() []byte
 }
@@ -55,6 +61,14 @@ func decodeTx(bs []byte) (types.Tx, error) {
 		return DecodeContractCallTx(bs)
 	case CONTRACT_DEPLOY:
 		return DecodeContractDeployTx(bs)
+	case CONTRACT_UPGRADE:
+		return DecodeContractUpgradeTx(bs)
+	case CONTRACT_INSTANTIATE:
+		return DecodeContractInstantiateTx(bs)
+	case VALIDATOR_UPDATE:
+		return DecodeValidatorUpdateTx(bs)
+	case ADMIN:
+		return DecodeAdminTx(bs)
 	default:
 		return nil, fmt.Errorf("unknown code '%v'", code)
 	}
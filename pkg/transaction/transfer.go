@@ -2,7 +2,6 @@ package transaction
 
 import (
 	"github.com/bluele/hypermint/pkg/abci/types"
-	"github.com/bluele/hypermint/pkg/util"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -20,6 +19,10 @@ func DecodeTransferTx(b []byte) (*TransferTx, error) {
 	return tx, rlp.DecodeBytes(b, tx)
 }
 
+func (tx *TransferTx) GetCommon() CommonTx {
+	return tx.Common
+}
+
 func (tx *TransferTx) SetSignature(sig []byte) {
 	tx.Common.SetSignature(sig)
 }
@@ -34,13 +37,17 @@ func (tx *TransferTx) ValidateBasic() types.Error {
 	if isEmptyAddr(tx.To) {
 		return ErrInvalidTransfer(DefaultCodespace, "tx.To == empty")
 	}
-	return tx.Common.VerifySignature(tx.GetSignBytes())
+	return nil
+}
+
+func (tx *TransferTx) VerifySignature(chainID string) types.Error {
+	return tx.Common.VerifySignature(tx.GetSignBytes(chainID))
 }
 
-func (tx *TransferTx) GetSignBytes() []byte {
+func (tx *TransferTx) GetSignBytes(chainID string) []byte {
 	ntx := *tx
 	ntx.SetSignature(nil)
-	return util.TxHash(ntx.Bytes())
+	return SignBytes(chainID, ntx.Bytes())
 }
 
 func (tx *TransferTx) Bytes() []byte {
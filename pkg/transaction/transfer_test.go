@@ -10,6 +10,8 @@ import (
 	cmn "github.com/tendermint/tendermint/libs/common"
 )
 
+const testChainID = "test-chain"
+
 func TestTransferTxEncoding(t *testing.T) {
 	var cases = []struct {
 		tx          *TransferTx
@@ -84,7 +86,7 @@ func TestTransferTxSignature(t *testing.T) {
 			&TransferTx{
 				Common: CommonTx{
 					From: crypto.PubkeyToAddress(fprv.PublicKey),
-					Gas:  0,
+					Gas:  1,
 				},
 				To:     crypto.PubkeyToAddress(tprv.PublicKey),
 				Amount: 100,
@@ -95,7 +97,7 @@ func TestTransferTxSignature(t *testing.T) {
 			&TransferTx{
 				Common: CommonTx{
 					From: crypto.PubkeyToAddress(fprv.PublicKey),
-					Gas:  0,
+					Gas:  1,
 				},
 				To:     crypto.PubkeyToAddress(tprv.PublicKey),
 				Amount: 0,
@@ -106,7 +108,7 @@ func TestTransferTxSignature(t *testing.T) {
 			&TransferTx{
 				Common: CommonTx{
 					From: crypto.PubkeyToAddress(fprv.PublicKey),
-					Gas:  0,
+					Gas:  1,
 				},
 				Amount: 100,
 			},
@@ -115,7 +117,18 @@ func TestTransferTxSignature(t *testing.T) {
 		{
 			&TransferTx{
 				Common: CommonTx{
-					Gas: 0,
+					Gas: 1,
+				},
+				To:     crypto.PubkeyToAddress(tprv.PublicKey),
+				Amount: 100,
+			},
+			false,
+		},
+		{
+			&TransferTx{
+				Common: CommonTx{
+					From: crypto.PubkeyToAddress(fprv.PublicKey),
+					Gas:  0,
 				},
 				To:     crypto.PubkeyToAddress(tprv.PublicKey),
 				Amount: 100,
@@ -128,13 +141,16 @@ func TestTransferTxSignature(t *testing.T) {
 		t.Run(fmt.Sprint(id), func(t *testing.T) {
 			assert := assert.New(t)
 			tx := cs.tx
-			sig, err := crypto.Sign(tx.GetSignBytes(), fprv)
+			sig, err := crypto.Sign(tx.GetSignBytes(testChainID), fprv)
 			assert.NoError(err)
 			tx.SetSignature(sig)
 
 			terr := tx.ValidateBasic()
 			if cs.valid {
 				assert.Nil(terr)
+				assert.Nil(tx.VerifySignature(testChainID))
+				// the signature must not be valid on other chains
+				assert.NotNil(tx.VerifySignature(testChainID + "-other"))
 			} else {
 				assert.NotNil(terr)
 			}
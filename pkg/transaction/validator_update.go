@@ -0,0 +1,94 @@
+package transaction
+
+import (
+	"fmt"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ValidatorUpdate adds, removes or re-weights a validator
+type ValidatorUpdate struct {
+	PubKey []byte // compressed secp256k1 public key of the validator
+	Power  uint64 // 0 removes the validator
+}
+
+// ValidatorUpdateTx updates the validator set. The sender must be an admin,
+// and the other admins sign the same bytes to satisfy the threshold of the admins.
+type ValidatorUpdateTx struct {
+	Common     CommonTx
+	Updates    []ValidatorUpdate
+	Signatures [][]byte // signatures of the other admins
+}
+
+func DecodeValidatorUpdateTx(b []byte) (*ValidatorUpdateTx, error) {
+	tx := new(ValidatorUpdateTx)
+	return tx, rlp.DecodeBytes(b, tx)
+}
+
+func (tx *ValidatorUpdateTx) GetCommon() CommonTx {
+	return tx.Common
+}
+
+func (tx *ValidatorUpdateTx) SetSignature(sig []byte) {
+	tx.Common.SetSignature(sig)
+}
+
+// AddSignature appends the signature of another admin
+func (tx *ValidatorUpdateTx) AddSignature(sig []byte) {
+	tx.Signatures = append(tx.Signatures, sig)
+}
+
+func (tx *ValidatorUpdateTx) Decode(b []byte) error {
+	return rlp.DecodeBytes(b, tx)
+}
+
+func (tx *ValidatorUpdateTx) ValidateBasic() types.Error {
+	if err := tx.Common.ValidateBasic(); err != nil {
+		return err
+	}
+	if len(tx.Updates) == 0 {
+		return ErrInvalidValidatorUpdate(DefaultCodespace, "len(tx.Updates) == 0")
+	}
+	for _, sig := range tx.Signatures {
+		if len(sig) == 0 {
+			return ErrInvalidValidatorUpdate(DefaultCodespace, "len(tx.Signatures[i]) == 0")
+		}
+	}
+	return nil
+}
+
+func (tx *ValidatorUpdateTx) VerifySignature(chainID string) types.Error {
+	return tx.Common.VerifySignature(tx.GetSignBytes(chainID))
+}
+
+// Signers returns the sender and the signers of tx.Signatures
+func (tx *ValidatorUpdateTx) Signers(chainID string) ([]common.Address, error) {
+	h := tx.GetSignBytes(chainID)
+	signers := []common.Address{tx.Common.From}
+	for i, sig := range tx.Signatures {
+		signer, err := recoverSigner(h, sig)
+		if err != nil {
+			return nil, fmt.Errorf("signatures[%v]: %v", i, err)
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+// GetSignBytes returns the bytes which both the sender and the other admins sign
+func (tx *ValidatorUpdateTx) GetSignBytes(chainID string) []byte {
+	ntx := *tx
+	ntx.SetSignature(nil)
+	ntx.Signatures = nil
+	return SignBytes(chainID, ntx.Bytes())
+}
+
+func (tx *ValidatorUpdateTx) Bytes() []byte {
+	b, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
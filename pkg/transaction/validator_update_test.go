@@ -0,0 +1,106 @@
+package transaction
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+func TestValidatorUpdateTxEncoding(t *testing.T) {
+	var cases = []struct {
+		tx          *ValidatorUpdateTx
+		decodeError bool
+	}{
+		{
+			&ValidatorUpdateTx{
+				Updates:    []ValidatorUpdate{{PubKey: cmn.RandBytes(33), Power: 10}, {PubKey: cmn.RandBytes(33)}},
+				Signatures: [][]byte{cmn.RandBytes(65)},
+				Common: CommonTx{
+					Code:      VALIDATOR_UPDATE,
+					From:      common.BytesToAddress(cmn.RandBytes(20)),
+					Nonce:     1,
+					Gas:       1,
+					Signature: cmn.RandBytes(65),
+				},
+			},
+			false,
+		},
+		{
+			&ValidatorUpdateTx{
+				Updates:    []ValidatorUpdate{{PubKey: cmn.RandBytes(33), Power: 10}},
+				Signatures: [][]byte{},
+				Common: CommonTx{
+					Code:      0,
+					From:      common.BytesToAddress(cmn.RandBytes(20)),
+					Nonce:     1,
+					Gas:       1,
+					Signature: cmn.RandBytes(65),
+				},
+			},
+			true,
+		},
+	}
+
+	for i, cs := range cases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			assert := assert.New(t)
+			b := cs.tx.Bytes()
+			tx1, err := DecodeValidatorUpdateTx(b)
+			assert.NoError(err)
+			assert.Equal(cs.tx, tx1)
+
+			tx2, err := DecodeTx(b)
+			if cs.decodeError {
+				assert.Error(err)
+				return
+			}
+			assert.NoError(err)
+			tx3, ok := tx2.(*ValidatorUpdateTx)
+			assert.True(ok)
+			assert.NotNil(tx3)
+		})
+	}
+}
+
+func TestValidatorUpdateTxSigners(t *testing.T) {
+	require := require.New(t)
+	prv1, err := crypto.GenerateKey()
+	require.NoError(err)
+	prv2, err := crypto.GenerateKey()
+	require.NoError(err)
+
+	tx := &ValidatorUpdateTx{
+		Common: CommonTx{
+			Code: VALIDATOR_UPDATE,
+			From: crypto.PubkeyToAddress(prv1.PublicKey),
+			Gas:  1,
+		},
+		Updates: []ValidatorUpdate{{PubKey: cmn.RandBytes(33), Power: 10}},
+	}
+	// the other admin signs the same bytes as the sender
+	sig2, err := crypto.Sign(tx.GetSignBytes(testChainID), prv2)
+	require.NoError(err)
+	tx.AddSignature(sig2)
+	sig1, err := crypto.Sign(tx.GetSignBytes(testChainID), prv1)
+	require.NoError(err)
+	tx.SetSignature(sig1)
+
+	require.Nil(tx.ValidateBasic())
+	require.Nil(tx.VerifySignature(testChainID))
+	signers, err := tx.Signers(testChainID)
+	require.NoError(err)
+	require.Equal([]common.Address{crypto.PubkeyToAddress(prv1.PublicKey), crypto.PubkeyToAddress(prv2.PublicKey)}, signers)
+
+	// the signatures are bound to the chain
+	signers, err = tx.Signers(testChainID + "-other")
+	require.NoError(err)
+	require.NotEqual(crypto.PubkeyToAddress(prv2.PublicKey), signers[1])
+
+	tx.Updates = nil
+	require.NotNil(tx.ValidateBasic())
+}
@@ -13,8 +13,7 @@ import (
 )
 
 func BytesToUint64(v []byte) (uint64, error) {
-	u, err := strconv.Atoi(string(v))
-	return uint64(u), err
+	return strconv.ParseUint(string(v), 10, 64)
 }
 
 func Uint64ToBytes(u uint64) []byte {
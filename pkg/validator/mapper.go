@@ -0,0 +1,249 @@
+package validator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+var (
+	validatorPrefix = []byte("validator/")
+	updatePrefix    = []byte("update/")
+	adminsKey       = []byte("admins")
+
+	ErrValidatorNotFound = errors.New("validator not found")
+)
+
+// MaxPower is the max power of a validator and the max total power of the validator set
+const MaxPower = uint64(tmtypes.MaxTotalVotingPower)
+
+// Validator is a validator of the chain
+type Validator struct {
+	// PubKey is the compressed secp256k1 public key of the validator
+	PubKey []byte
+	// Power is the voting power of the validator. 0 means the validator is removed.
+	// It must not exceed MaxPower.
+	Power uint64
+}
+
+// NewValidator returns a validator of the public key
+func NewValidator(pub crypto.PubKey, power uint64) (Validator, error) {
+	spub, ok := pub.(secp256k1.PubKeySecp256k1)
+	if !ok {
+		return Validator{}, fmt.Errorf("unsupported public key type: %T", pub)
+	}
+	v := Validator{PubKey: spub[:], Power: power}
+	return v, v.Validate()
+}
+
+// ValidatorFromABCI returns a validator of the validator update of ABCI
+func ValidatorFromABCI(vu abci.ValidatorUpdate) (Validator, error) {
+	if vu.Power < 0 {
+		return Validator{}, fmt.Errorf("negative power: %v", vu.Power)
+	}
+	pub, err := tmtypes.PB2TM.PubKey(vu.PubKey)
+	if err != nil {
+		return Validator{}, err
+	}
+	return NewValidator(pub, uint64(vu.Power))
+}
+
+// Validate returns an error if the public key is invalid or the power is too large
+func (v Validator) Validate() error {
+	if l := len(v.PubKey); l != secp256k1.PubKeySecp256k1Size {
+		return fmt.Errorf("invalid public key size: %v", l)
+	}
+	if v.Power > MaxPower {
+		return fmt.Errorf("power must not exceed %v: %v", MaxPower, v.Power)
+	}
+	return nil
+}
+
+// CryptoPubKey returns the public key of tendermint
+func (v Validator) CryptoPubKey() crypto.PubKey {
+	var pub secp256k1.PubKeySecp256k1
+	copy(pub[:], v.PubKey)
+	return pub
+}
+
+// Address returns the consensus address of the validator
+func (v Validator) Address() crypto.Address {
+	return v.CryptoPubKey().Address()
+}
+
+// ABCIValidatorUpdate returns the validator update which is sent to tendermint.
+// The power of a valid validator always fits in int64.
+func (v Validator) ABCIValidatorUpdate() abci.ValidatorUpdate {
+	return tmtypes.TM2PB.NewValidatorUpdate(v.CryptoPubKey(), int64(v.Power))
+}
+
+// TotalPower returns the total power of the validators, or an error if it exceeds MaxPower
+func TotalPower(vs []Validator) (uint64, error) {
+	var total uint64
+	for _, v := range vs {
+		if v.Power > MaxPower-total {
+			return 0, fmt.Errorf("total power must not exceed %v", MaxPower)
+		}
+		total += v.Power
+	}
+	return total, nil
+}
+
+// Admins are the accounts which can update the validator set
+type Admins struct {
+	Addresses []common.Address `json:"addresses"`
+	// Threshold is the number of the admins who must sign an update
+	Threshold uint32 `json:"threshold"`
+}
+
+// Validate returns an error if the threshold is out of range or the addresses are duplicated
+func (a Admins) Validate() error {
+	if a.Threshold == 0 || int(a.Threshold) > len(a.Addresses) {
+		return fmt.Errorf("threshold must be in [1, %v]: %v", len(a.Addresses), a.Threshold)
+	}
+	seen := make(map[common.Address]bool)
+	for _, addr := range a.Addresses {
+		if seen[addr] {
+			return fmt.Errorf("duplicated admin: %v", addr.Hex())
+		}
+		seen[addr] = true
+	}
+	return nil
+}
+
+// Has returns true if the address is an admin
+func (a Admins) Has(addr common.Address) bool {
+	for _, admin := range a.Addresses {
+		if admin == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatorMapper is a store of the validator set and the admins who manage it
+type ValidatorMapper interface {
+	GetAdmins(ctx types.Context) (Admins, bool)
+	SetAdmins(ctx types.Context, admins Admins) error
+	// GetValidator returns the validator of the consensus address
+	GetValidator(ctx types.Context, addr crypto.Address) (Validator, error)
+	// Validators returns the validators in the order of their addresses
+	Validators(ctx types.Context) []Validator
+	// SetValidator stores the validator. If the power is 0, the validator is removed.
+	SetValidator(ctx types.Context, v Validator)
+	// UpdateValidator stores the validator, and records the update to be sent to tendermint
+	UpdateValidator(ctx types.Context, v Validator)
+	// PopUpdates returns the recorded updates in the order of the addresses, and clears them
+	PopUpdates(ctx types.Context) []Validator
+}
+
+type validatorMapper struct {
+	storeKey types.StoreKey
+}
+
+func NewValidatorMapper(storeKey types.StoreKey) ValidatorMapper {
+	return &validatorMapper{
+		storeKey: storeKey,
+	}
+}
+
+func (vm *validatorMapper) GetAdmins(ctx types.Context) (Admins, bool) {
+	b := ctx.KVStore(vm.storeKey).Get(adminsKey)
+	if b == nil {
+		return Admins{}, false
+	}
+	var admins Admins
+	if err := rlp.DecodeBytes(b, &admins); err != nil {
+		panic(err)
+	}
+	return admins, true
+}
+
+func (vm *validatorMapper) SetAdmins(ctx types.Context, admins Admins) error {
+	if err := admins.Validate(); err != nil {
+		return err
+	}
+	b, err := rlp.EncodeToBytes(admins)
+	if err != nil {
+		return err
+	}
+	ctx.KVStore(vm.storeKey).Set(adminsKey, b)
+	return nil
+}
+
+func (vm *validatorMapper) GetValidator(ctx types.Context, addr crypto.Address) (Validator, error) {
+	b := vm.getStore(ctx, validatorPrefix).Get(addr)
+	if b == nil {
+		return Validator{}, ErrValidatorNotFound
+	}
+	return decodeValidator(b), nil
+}
+
+func (vm *validatorMapper) Validators(ctx types.Context) []Validator {
+	return vm.iterate(ctx, validatorPrefix)
+}
+
+func (vm *validatorMapper) SetValidator(ctx types.Context, v Validator) {
+	kvs := vm.getStore(ctx, validatorPrefix)
+	if v.Power == 0 {
+		kvs.Delete(v.Address())
+		return
+	}
+	kvs.Set(v.Address(), encodeValidator(v))
+}
+
+func (vm *validatorMapper) UpdateValidator(ctx types.Context, v Validator) {
+	vm.SetValidator(ctx, v)
+	vm.getStore(ctx, updatePrefix).Set(v.Address(), encodeValidator(v))
+}
+
+func (vm *validatorMapper) PopUpdates(ctx types.Context) []Validator {
+	updates := vm.iterate(ctx, updatePrefix)
+	kvs := vm.getStore(ctx, updatePrefix)
+	for _, v := range updates {
+		kvs.Delete(v.Address())
+	}
+	return updates
+}
+
+func (vm *validatorMapper) iterate(ctx types.Context, prefix []byte) []Validator {
+	var vs []Validator
+	it := vm.getStore(ctx, prefix).Iterator(nil, nil)
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		vs = append(vs, decodeValidator(it.Value()))
+	}
+	sort.Slice(vs, func(i, j int) bool {
+		return bytes.Compare(vs[i].Address(), vs[j].Address()) < 0
+	})
+	return vs
+}
+
+func (vm *validatorMapper) getStore(ctx types.Context, prefix []byte) types.KVStore {
+	return ctx.KVStore(vm.storeKey).Prefix(prefix)
+}
+
+func encodeValidator(v Validator) []byte {
+	b, err := rlp.EncodeToBytes(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func decodeValidator(b []byte) Validator {
+	var v Validator
+	if err := rlp.DecodeBytes(b, &v); err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -1,5 +1,5 @@
 extern crate hmcdk;
-use hmcdk::api::{emit_event, get_arg, get_sender, read_state, write_state, ecrecover_address, get_contract_address, keccak256, sha256, call_contract};
+use hmcdk::api::{emit_event, get_arg, get_sender, read_state, write_state, delete_state, iterate_prefix, ecrecover_address, get_contract_address, get_block_height, get_block_time, get_chain_id, get_proposer, get_tx_hash, get_balance, get_value, transfer, keccak256, sha256, call_contract, static_call, lock_reentrancy, create_contract_from_hash, get_call_error, revert_with_code};
 use hmcdk::error;
 use hmcdk::prelude::*;
 
@@ -26,6 +26,26 @@ pub fn test_get_contract_address() -> R<Address> {
     Ok(Some(get_contract_address()?))
 }
 
+// returns height(8byte)|time(8byte)|proposer(20byte)|tx_hash(32byte)|chain_id
+#[contract]
+pub fn test_get_block_context() -> R<Vec<u8>> {
+    let mut v: Vec<u8> = vec![];
+    v.extend_from_slice(&get_block_height().to_be_bytes());
+    v.extend_from_slice(&get_block_time().to_be_bytes());
+    v.extend_from_slice(&get_proposer()?);
+    v.extend_from_slice(&get_tx_hash()?);
+    v.extend_from_slice(get_chain_id()?.as_bytes());
+    Ok(Some(v))
+}
+
+// sends the received value to the address, and returns the balance of the contract
+#[contract]
+pub fn test_transfer() -> R<u64> {
+    let to: Address = get_arg(0)?;
+    transfer(&to, get_value()?)?;
+    Ok(Some(get_balance(&get_contract_address()?)?))
+}
+
 #[contract]
 pub fn test_get_arguments() -> R<Vec<u8>> {
     let argIdx: i32 = get_arg(0)?;
@@ -113,6 +133,25 @@ pub fn test_write_to_multiple_key() -> R<i32> {
     Ok(None)
 }
 
+#[contract]
+pub fn test_delete_state() -> R<i32> {
+    let key: Vec<u8> = get_arg(0)?;
+    delete_state(&key)?;
+    Ok(None)
+}
+
+// returns the concatenation of values whose keys start with the prefix
+#[contract]
+pub fn test_iterate_state() -> R<Vec<u8>> {
+    let prefix: Vec<u8> = get_arg(0)?;
+    let limit: i32 = get_arg(1)?;
+    let mut values: Vec<u8> = vec![];
+    for (_, v) in iterate_prefix(&prefix, limit as usize)? {
+        values.extend_from_slice(&v);
+    }
+    Ok(Some(values))
+}
+
 #[contract]
 pub fn test_keccak256() -> R<Vec<u8>> {
     let msg: Vec<u8> = get_arg(0)?;
@@ -169,6 +208,79 @@ pub fn test_call_get_contract_address_on_external_contract() -> R<Vec<u8>> {
     Ok(Some(call_contract(&addr, "get_contract_address".as_bytes(), vec![])?))
 }
 
+// tries to delete the key via static call to itself
+#[contract]
+pub fn test_static_call_delete_state() -> R<i32> {
+    let key: Vec<u8> = get_arg(0)?;
+    let addr = get_contract_address()?;
+    let _: Vec<u8> = static_call(&addr, "test_delete_state".as_bytes(), vec![&key])?;
+    Ok(None)
+}
+
+#[contract]
+pub fn test_static_call_read_state() -> R<Vec<u8>> {
+    let key: Vec<u8> = get_arg(0)?;
+    let addr = get_contract_address()?;
+    Ok(Some(static_call(&addr, "test_read_state".as_bytes(), vec![&key])?))
+}
+
+// calls itself recursively until the call fails
+#[contract]
+pub fn test_recursive_call() -> R<i32> {
+    let addr = get_contract_address()?;
+    let _: Vec<u8> = call_contract(&addr, "test_recursive_call".as_bytes(), vec![])?;
+    Ok(None)
+}
+
+#[contract]
+pub fn test_locked_reentrant_call() -> R<Address> {
+    lock_reentrancy();
+    let addr = get_contract_address()?;
+    Ok(Some(call_contract(&addr, "test_get_sender".as_bytes(), vec![])?))
+}
+
+#[contract]
+pub fn test_reentrant_call() -> R<Address> {
+    let addr = get_contract_address()?;
+    Ok(Some(call_contract(&addr, "test_get_sender".as_bytes(), vec![])?))
+}
+
+// returns code(4byte)|message of the failed call
+#[contract]
+pub fn test_get_call_error() -> R<Vec<u8>> {
+    let addr = get_contract_address()?;
+    if call_contract::<Vec<u8>>(&addr, "test_get_arguments".as_bytes(), vec![]).is_ok() {
+        return Err(error::from_str("call must fail"));
+    }
+    let (code, msg) = get_call_error().ok_or(error::from_str("call error not found"))?;
+    let mut v: Vec<u8> = vec![];
+    v.extend_from_slice(&code.to_be_bytes());
+    v.extend_from_slice(msg.as_bytes());
+    Ok(Some(v))
+}
+
+#[contract]
+pub fn test_revert() -> R<i32> {
+    let code: i32 = get_arg(0)?;
+    revert_with_code(code as u32, "reverted")
+}
+
+// the caller cannot catch the revert of the callee
+#[contract]
+pub fn test_call_revert() -> R<i32> {
+    let code: Vec<u8> = get_arg(0)?;
+    let addr = get_contract_address()?;
+    let _ = call_contract::<Vec<u8>>(&addr, "test_revert".as_bytes(), vec![&code]);
+    Ok(None)
+}
+
+// creates a new contract from the code hash, and returns the address of it
+#[contract]
+pub fn test_create_contract() -> R<Address> {
+    let code_hash: Vec<u8> = get_arg(0)?;
+    Ok(Some(create_contract_from_hash(&code_hash, vec![])?))
+}
+
 #[contract]
 pub fn init() -> R<i32> {
     Ok(None)
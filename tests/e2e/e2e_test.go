@@ -252,13 +252,13 @@ func (ts *E2ETestSuite) GetBalance(ctx context.Context, addr common.Address) (in
 }
 
 func (ts *E2ETestSuite) Transfer(ctx context.Context, from, to common.Address, amount int) error {
-	cmd := fmt.Sprintf("transfer --address=%v --amount=10 --to=%v --gas=1 --password=password", from.Hex(), to.Hex())
+	cmd := fmt.Sprintf("transfer --address=%v --amount=10 --to=%v --gas=10000000 --password=password", from.Hex(), to.Hex())
 	_, err := ts.sendTxCMD(ctx, cmd)
 	return err
 }
 
 func (ts *E2ETestSuite) DeployContract(ctx context.Context, from common.Address, path string) (common.Address, error) {
-	cmd := fmt.Sprintf("contract deploy --address=%v --path=%v --gas=1 --password=password", from.Hex(), path)
+	cmd := fmt.Sprintf("contract deploy --address=%v --path=%v --gas=10000000 --password=password", from.Hex(), path)
 	address, err := ts.sendTxCMD(ctx, cmd)
 	if err != nil {
 		return common.Address{}, err
@@ -268,7 +268,7 @@ func (ts *E2ETestSuite) DeployContract(ctx context.Context, from common.Address,
 
 func (ts *E2ETestSuite) CallContract(ctx context.Context, from, contractAddress common.Address, fn string, args []string, argTypes []string, retType string, isSimulate bool) (interface{}, error) {
 	cmd := fmt.Sprintf(
-		`contract call --address=%v --contract=%v --func="%v" --args=%#v --argtypes=%#v --password=password --gas=1`,
+		`contract call --address=%v --contract=%v --func="%v" --args=%#v --argtypes=%#v --password=password --gas=10000000`,
 		from.Hex(),
 		contractAddress.Hex(),
 		fn,
@@ -6,17 +6,21 @@ import (
 	"fmt"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/bluele/hypermint/pkg/abci/store"
 	sdk "github.com/bluele/hypermint/pkg/abci/types"
+	"github.com/bluele/hypermint/pkg/account"
 	"github.com/bluele/hypermint/pkg/contract"
 	"github.com/bluele/hypermint/pkg/db"
 	"github.com/bluele/hypermint/pkg/util"
 	"github.com/bluele/hypermint/pkg/util/wallet"
 
+	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/suite"
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 	"github.com/tendermint/tendermint/libs/common"
 	dbm "github.com/tendermint/tm-db"
 	bip39 "github.com/tyler-smith/go-bip39"
@@ -117,6 +121,164 @@ func (ts *ContractTestSuite) TestEnv() {
 	}
 }
 
+func (ts *ContractTestSuite) TestBlockContext() {
+	cms := ts.cmsProvider()
+
+	proposer := ethcmn.BytesToAddress(common.RandBytes(20))
+	txBytes := common.RandBytes(32)
+	header := abci.Header{
+		ChainID:         "test-chain",
+		Height:          10,
+		Time:            time.Unix(1500000000, 0),
+		ProposerAddress: proposer.Bytes(),
+	}
+	env := &contract.Env{
+		Sender:   crypto.PubkeyToAddress(ts.owner.PublicKey),
+		Contract: &ts.contract,
+		DB:       db.NewVersionedDB(cms.GetKVStore(ts.mainKey)),
+	}
+	res, err := env.Exec(sdk.NewContext(cms, header, false, nil).WithTxBytes(txBytes), "test_get_block_context")
+	ts.NoError(err)
+
+	var expected []byte
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], 10)
+	expected = append(expected, b[:]...)
+	binary.BigEndian.PutUint64(b[:], 1500000000)
+	expected = append(expected, b[:]...)
+	expected = append(expected, proposer.Bytes()...)
+	expected = append(expected, tmhash.Sum(txBytes)...)
+	expected = append(expected, []byte(header.ChainID)...)
+	ts.Equal(expected, res.Response)
+}
+
+func (ts *ContractTestSuite) TestTransfer() {
+	cms := ts.cmsProvider()
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	am := account.NewAccountMapper(ts.mainKey)
+
+	to := ethcmn.BytesToAddress(common.RandBytes(20))
+	_, err := am.AddBalance(ctx, ts.contract.Address(), 100)
+	ts.NoError(err)
+
+	var exec = func(value uint64) (*contract.Result, error) {
+		env := &contract.Env{
+			Sender:        crypto.PubkeyToAddress(ts.owner.PublicKey),
+			Contract:      &ts.contract,
+			DB:            db.NewVersionedDB(cms.GetKVStore(ts.mainKey)),
+			AccountMapper: am,
+			Value:         value,
+			Args:          contract.NewArgs([][]byte{to.Bytes()}),
+		}
+		return env.Exec(ctx, "test_transfer")
+	}
+
+	res, err := exec(30)
+	ts.NoError(err)
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], 70)
+	ts.Equal(b[:], res.Response)
+	balance, err := am.GetBalance(ctx, to)
+	ts.NoError(err)
+	ts.EqualValues(30, balance)
+
+	// the contract cannot send coin over its balance
+	_, err = exec(71)
+	ts.Error(err)
+}
+
+func (ts *ContractTestSuite) TestCreateContract() {
+	cms := ts.cmsProvider()
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	am := account.NewAccountMapper(ts.mainKey)
+	cm := contract.NewContractMapper(ts.mainKey)
+	h := cm.PutCode(ctx, ts.contract.Code)
+
+	env := &contract.Env{
+		Sender:        crypto.PubkeyToAddress(ts.owner.PublicKey),
+		Contract:      &ts.contract,
+		DB:            db.NewVersionedDB(cms.GetKVStore(ts.mainKey)),
+		EnvManager:    contract.NewEnvManager(ts.mainKey, cm, am),
+		AccountMapper: am,
+		Args:          contract.NewArgs([][]byte{h.Bytes()}),
+	}
+	res, err := env.Exec(ctx, "test_create_contract")
+	ts.NoError(err)
+	addr := contract.NewContractAddress(ts.contract.Address(), 0)
+	ts.Equal(addr.Bytes(), res.Response)
+
+	c, err := cm.Get(ctx, addr)
+	ts.NoError(err)
+	ts.Equal(ts.contract.Address(), c.Owner)
+	ts.Equal(ts.contract.Code, c.Code)
+	// RWSets of the created contract are merged into the creator's ones
+	ts.Len(res.State.RWSets(), 2)
+}
+
+func (ts *ContractTestSuite) TestNestedCall() {
+	cms := ts.cmsProvider()
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	cm := contract.NewContractMapper(ts.mainKey)
+	em := contract.NewEnvManager(ts.mainKey, cm, account.NewAccountMapper(ts.mainKey))
+	addr := ts.contract.Address()
+	cm.PutCode(ctx, ts.contract.Code)
+	cm.Put(ctx, addr, &ts.contract)
+
+	var exec = func(fname string, args [][]byte) (*contract.Result, error) {
+		env, err := em.Get(ctx, crypto.PubkeyToAddress(ts.owner.PublicKey), addr, contract.NewArgs(args))
+		ts.NoError(err)
+		return env.Exec(ctx, fname)
+	}
+
+	// the callee of static call cannot modify any state
+	_, err := exec("test_static_call_delete_state", [][]byte{[]byte("key")})
+	ts.Error(err)
+	res, err := exec("test_static_call_read_state", [][]byte{[]byte("key")})
+	ts.NoError(err)
+	ts.Len(res.State.RWSets(), 2)
+
+	// the call fails when the depth exceeds the max call depth
+	params := contract.DefaultVMParams()
+	params.MaxCallDepth = 4
+	ts.NoError(em.SetParams(ctx, params))
+	_, err = exec("test_recursive_call", nil)
+	ts.Error(err)
+
+	res, err = exec("test_reentrant_call", nil)
+	ts.NoError(err)
+	ts.Equal(addr.Bytes(), res.Response)
+	_, err = exec("test_locked_reentrant_call", nil)
+	ts.Error(err)
+}
+
+func (ts *ContractTestSuite) TestCallError() {
+	cms := ts.cmsProvider()
+	ctx := sdk.NewContext(cms, abci.Header{}, false, nil)
+	cm := contract.NewContractMapper(ts.mainKey)
+	em := contract.NewEnvManager(ts.mainKey, cm, account.NewAccountMapper(ts.mainKey))
+	addr := ts.contract.Address()
+	cm.PutCode(ctx, ts.contract.Code)
+	cm.Put(ctx, addr, &ts.contract)
+
+	var exec = func(fname string, args [][]byte) (*contract.Result, error) {
+		env, err := em.Get(ctx, crypto.PubkeyToAddress(ts.owner.PublicKey), addr, contract.NewArgs(args))
+		ts.NoError(err)
+		return env.Exec(ctx, fname)
+	}
+
+	res, err := exec("test_get_call_error", nil)
+	ts.NoError(err)
+	ts.Equal([]byte{0xff, 0xff, 0xff, 0xff}, res.Response[:4])
+	ts.NotEmpty(res.Response[4:])
+
+	var code [4]byte
+	binary.BigEndian.PutUint32(code[:], 5)
+	for _, fname := range []string{"test_revert", "test_call_revert"} {
+		_, err = exec(fname, [][]byte{code[:]})
+		ts.Equal(&contract.RevertError{Address: addr.Hex(), Code: 5, Message: "reverted"}, err)
+	}
+}
+
 func (ts *ContractTestSuite) TestKeccak256() {
 	cms := ts.cmsProvider()
 
@@ -253,6 +415,60 @@ func (ts *ContractTestSuite) TestReadWriteState() {
 	}
 }
 
+func (ts *ContractTestSuite) TestDeleteAndIterateState() {
+	cms := ts.cmsProvider()
+	var txIndex uint32 = 0
+
+	var U32 = func(v uint32) []byte {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], v)
+		return b[:]
+	}
+	var sendTx = func(fname string, args [][]byte) *contract.Result {
+		addr := ts.contract.Address()
+		env := &contract.Env{
+			Sender:   crypto.PubkeyToAddress(ts.owner.PublicKey),
+			Contract: &ts.contract,
+			DB:       db.NewVersionedDB(cms.GetKVStore(ts.mainKey).Prefix(addr[:])),
+			Args:     contract.NewArgs(args),
+		}
+		res, err := env.Exec(sdk.NewContext(cms, abci.Header{}, false, nil), fname)
+		if err != nil {
+			ts.FailNow("failed to Exec", err.Error())
+		}
+		ts.NoError(db.CommitState(cms.GetKVStore(ts.mainKey), res.State.RWSets(), db.Version{1, txIndex}, db.NewKeyMaps()))
+		cms.Commit()
+		txIndex++
+		return res
+	}
+
+	for _, kv := range [][]string{{"p/a", "A"}, {"p/b", "B"}, {"p/c", "C"}, {"q", "Q"}} {
+		sendTx("test_write_state", [][]byte{[]byte(kv[0]), []byte(kv[1])})
+	}
+
+	res := sendTx("test_iterate_state", [][]byte{[]byte("p/"), U32(0)})
+	ts.Equal([]byte("ABC"), res.Response)
+	rrs := res.State.RWSets()[0].Items.RangeReadSet
+	if ts.Len(rrs, 1) {
+		ts.Equal([]byte("p/"), rrs[0].Start)
+		ts.Equal([]byte("p0"), rrs[0].End)
+	}
+
+	res = sendTx("test_iterate_state", [][]byte{[]byte("p/"), U32(2)})
+	ts.Equal([]byte("AB"), res.Response)
+	rrs = res.State.RWSets()[0].Items.RangeReadSet
+	if ts.Len(rrs, 1) {
+		ts.Equal([]byte("p/"), rrs[0].Start)
+		ts.Equal([]byte("p/c"), rrs[0].End)
+	}
+
+	res = sendTx("test_delete_state", [][]byte{[]byte("p/b")})
+	ts.Equal([]db.Write{{Key: []byte("p/b"), IsDelete: true}}, res.State.RWSets()[0].Items.WriteSet)
+
+	res = sendTx("test_iterate_state", [][]byte{[]byte("p/"), U32(0)})
+	ts.Equal([]byte("AC"), res.Response)
+}
+
 func (ts *ContractTestSuite) TestReadWriteSet() {
 	const height uint32 = 1
 	var txIndex uint32 = 0
@@ -68,12 +68,14 @@ func (ts *TransferTestSuite) TestTransfer() {
 	for i, s := range steps {
 		ts.Run(fmt.Sprint(i), func() {
 			ctx := ts.GetNodeClientContext(ts.CliDir, s.sender)
+			nonce, err := ctx.GetNonceByAddress(s.sender)
+			ts.NoError(err)
 			tx := &transaction.TransferTx{
 				Common: transaction.CommonTx{
 					Code:  transaction.TRANSFER,
 					From:  s.sender,
-					Gas:   1,
-					Nonce: uint64(time.Now().UnixNano()),
+					Gas:   100000,
+					Nonce: nonce,
 				},
 				To:     s.receiver,
 				Amount: s.amount,
@@ -99,6 +101,41 @@ func (ts *TransferTestSuite) TestTransfer() {
 	}
 }
 
+func (ts *TransferTestSuite) TestTransferReplay() {
+	ownerAddr := crypto.PubkeyToAddress(ts.owner.PublicKey)
+	aliceAddr := crypto.PubkeyToAddress(ts.alice.PublicKey)
+
+	ctx := ts.GetNodeClientContext(ts.CliDir, ownerAddr)
+	nonce, err := ctx.GetNonceByAddress(ownerAddr)
+	ts.NoError(err)
+
+	tx := &transaction.TransferTx{
+		Common: transaction.CommonTx{
+			Code:  transaction.TRANSFER,
+			From:  ownerAddr,
+			Gas:   100000,
+			Nonce: nonce,
+		},
+		To:     aliceAddr,
+		Amount: 1,
+	}
+	ts.NoError(ctx.SignAndBroadcastTx(tx, ownerAddr))
+	time.Sleep(2 * ts.Config.Consensus.TimeoutCommit)
+
+	next, err := ctx.GetNonceByAddress(ownerAddr)
+	ts.NoError(err)
+	ts.Equal(nonce+1, next)
+
+	// the same signed tx must be rejected
+	_, err = ctx.BroadcastTx(tx.Bytes())
+	ts.Error(err)
+
+	// a stale nonce must be rejected
+	tx.Common.Nonce = nonce
+	tx.Amount = 2
+	ts.Error(ctx.SignAndBroadcastTx(tx, ownerAddr))
+}
+
 func (ts *TransferTestSuite) TearDownSuite() {
 	ts.NodeTestSuite.TearDownSuite()
 }